@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -10,7 +13,11 @@ import (
 	"hydectl/internal/tui"
 )
 
-var previewHighlightStyle string
+var (
+	previewHighlightStyle string
+	configJSON            bool
+	configEditLast        bool
+)
 
 var configCmd = &cobra.Command{
 	Use:   "config",
@@ -19,8 +26,416 @@ var configCmd = &cobra.Command{
 	Run:   runConfigCommand,
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config registry",
+	Long:  `Load every config registry layer and report unknown keys, missing files or paths, unresolvable variables and hooks that are not in PATH. Exits non-zero when problems are found.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		issues, err := config.ValidateRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+
+		if len(issues) > 0 {
+			fmt.Printf("\n❌ %d problem(s) found in config registry\n", len(issues))
+			os.Exit(1)
+		}
+		fmt.Println("✅ Config registry is valid")
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit <app> [file...]",
+	Short: "Edit registered config files directly",
+	Long:  `Open config files from the registry in the editor without the interactive selector, running their pre/post hooks. App and file names may be abbreviated. When several files of an app are given, hooks inherited from the app run only once. With --last, the most recently edited file is reopened.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if configEditLast {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		if configEditLast {
+			recent, err := config.LoadRecentFiles()
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			last, ok := recent.Last()
+			if !ok {
+				fmt.Println("No config file has been edited yet.")
+				os.Exit(1)
+			}
+			if _, ok := registry.Apps[last.App].Files[last.File]; !ok {
+				fmt.Printf("Last edited file %s/%s is no longer in the config registry.\n", last.App, last.File)
+				os.Exit(1)
+			}
+			config.EditConfigFile(last.App, last.File, registry.Apps[last.App].ResolveFile(last.File))
+			return
+		}
+
+		if len(args) <= 2 {
+			appName, fileName, err := resolveConfigFile(registry, args)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			config.EditConfigFile(appName, fileName, registry.Apps[appName].ResolveFile(fileName))
+			return
+		}
+
+		appName, err := registry.FindApp(args[0])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		var fileNames []string
+		for _, query := range args[1:] {
+			fileName, err := registry.FindFile(appName, query)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			fileNames = append(fileNames, fileName)
+		}
+
+		config.EditConfigFiles(appName, registry.Apps[appName], fileNames)
+	},
+	ValidArgsFunction: completeConfigFiles,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered apps and config files",
+	Long:  `List every app and config file in the registry with its description, expanded path and whether the file exists.`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		if configJSON {
+			apps := make([]configAppJSON, 0, len(registry.AppsOrder))
+			for _, appName := range registry.AppsOrder {
+				app := registry.Apps[appName]
+				entry := configAppJSON{
+					Name:        appName,
+					Description: app.Description,
+					Icon:        app.Icon,
+					Files:       make([]configFileJSON, 0, len(app.Files)),
+				}
+				for _, fileName := range app.FileNames() {
+					entry.Files = append(entry.Files, newConfigFileJSON(appName, fileName, app.Files[fileName]))
+				}
+				apps = append(apps, entry)
+			}
+			printJSON(apps)
+			return
+		}
+
+		for _, appName := range registry.AppsOrder {
+			app := registry.Apps[appName]
+			icon := app.Icon
+			if icon == "" {
+				icon = "⚙️"
+			}
+			fmt.Printf("%s %s", icon, appName)
+			if app.Description != "" {
+				fmt.Printf(" - %s", app.Description)
+			}
+			fmt.Println()
+
+			for _, fileName := range app.FileNames() {
+				fileConfig := app.Files[fileName]
+				status := "✓"
+				if !fileConfig.FileExists() {
+					status = "✗"
+				}
+				fmt.Printf("   %s %-14s %s", status, fileName, config.ExpandPath(fileConfig.Path))
+				if fileConfig.Description != "" {
+					fmt.Printf("  (%s)", fileConfig.Description)
+				}
+				fmt.Println()
+			}
+		}
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path <app> [file]",
+	Short: "Print the resolved path of a config file",
+	Long:  `Print the expanded path of a config file from the registry. App and file names may be abbreviated.`,
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		appName, fileName, err := resolveConfigFile(registry, args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fileConfig := registry.Apps[appName].Files[fileName]
+		if configJSON {
+			printJSON(newConfigFileJSON(appName, fileName, fileConfig))
+			return
+		}
+		fmt.Println(config.ExpandPath(fileConfig.Path))
+	},
+	ValidArgsFunction: completeConfigFile,
+}
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "history <app> [file]",
+	Short: "List backups of a config file",
+	Long:  `List the snapshots hydectl took of a config file before editing it, newest first.`,
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		appName, fileName, err := resolveConfigFile(registry, args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		backups, err := config.ListBackups(appName, fileName)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if configJSON {
+			printJSON(backups)
+			return
+		}
+
+		if len(backups) == 0 {
+			fmt.Printf("No backups found for %s/%s\n", appName, fileName)
+			return
+		}
+		for i, backup := range backups {
+			fmt.Printf("%3d  %s  %s  %d bytes\n", i+1, backup.ID, backup.Time.Format("2006-01-02 15:04:05"), backup.Size)
+		}
+	},
+	ValidArgsFunction: completeConfigFile,
+}
+
+var configRestoreCmd = &cobra.Command{
+	Use:   "restore <app> <file> [backup]",
+	Short: "Restore a config file from a backup",
+	Long:  `Restore a config file from a backup listed by "config history", given by its number or ID. Defaults to the latest backup. The current contents are backed up first.`,
+	Args:  cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		appName, fileName, err := resolveConfigFile(registry, args[:2])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		ref := ""
+		if len(args) > 2 {
+			ref = args[2]
+		}
+		backup, err := config.FindBackup(appName, fileName, ref)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fileConfig := registry.Apps[appName].ResolveFile(fileName)
+		if err := config.RestoreConfigFile(appName, fileName, fileConfig, *backup); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	},
+	ValidArgsFunction: completeConfigFile,
+}
+
+var configGrepCmd = &cobra.Command{
+	Use:   "grep <pattern>",
+	Short: "Search the contents of all registered config files",
+	Long:  `Search every config file in the registry for a case-insensitive regular expression and print the matching lines as app/file:line. Patterns that are not valid regular expressions are matched literally. Exits non-zero when nothing matches.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		registry, err := config.LoadConfigRegistry()
+		if err != nil {
+			fmt.Printf("Error loading config registry: %v\n", err)
+			os.Exit(1)
+		}
+
+		matches := registry.Grep(config.CompileGrepPattern(args[0]))
+
+		if configJSON {
+			if matches == nil {
+				matches = []config.GrepMatch{}
+			}
+			printJSON(matches)
+		} else {
+			for _, match := range matches {
+				fmt.Printf("%s/%s:%d: %s\n", match.App, match.File, match.Line, strings.TrimSpace(match.Text))
+			}
+		}
+
+		if len(matches) == 0 {
+			if !configJSON {
+				fmt.Printf("No matches for %q\n", args[0])
+			}
+			os.Exit(1)
+		}
+	},
+}
+
+type configAppJSON struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Icon        string           `json:"icon"`
+	Files       []configFileJSON `json:"files"`
+}
+
+type configFileJSON struct {
+	App          string `json:"app"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Path         string `json:"path"`
+	ExpandedPath string `json:"expanded_path"`
+	Exists       bool   `json:"exists"`
+}
+
+func newConfigFileJSON(appName, fileName string, fileConfig config.ConfigFile) configFileJSON {
+	return configFileJSON{
+		App:          appName,
+		Name:         fileName,
+		Description:  fileConfig.Description,
+		Path:         fileConfig.Path,
+		ExpandedPath: config.ExpandPath(fileConfig.Path),
+		Exists:       fileConfig.FileExists(),
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Printf("Error encoding JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// resolveConfigFile maps "<app> [file]" arguments onto registry entries.
+func resolveConfigFile(registry *config.OrderedConfigRegistry, args []string) (string, string, error) {
+	appName, err := registry.FindApp(args[0])
+	if err != nil {
+		return "", "", err
+	}
+
+	fileQuery := ""
+	if len(args) > 1 {
+		fileQuery = args[1]
+	}
+	fileName, err := registry.FindFile(appName, fileQuery)
+	if err != nil {
+		return "", "", err
+	}
+
+	return appName, fileName, nil
+}
+
+// completeConfigFile completes app names, then the files of the chosen app.
+func completeConfigFile(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	registry, err := config.LoadConfigRegistry()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	switch len(args) {
+	case 0:
+		for _, appName := range registry.AppsOrder {
+			completions = append(completions, appName+"\t"+registry.Apps[appName].Description)
+		}
+	case 1:
+		appName, err := registry.FindApp(args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		app := registry.Apps[appName]
+		for _, fileName := range app.FileNames() {
+			completions = append(completions, fileName+"\t"+app.Files[fileName].Description)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigFiles completes an app name followed by any number of its
+// files, skipping files already given.
+func completeConfigFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) < 2 {
+		return completeConfigFile(cmd, args, toComplete)
+	}
+
+	completions, directive := completeConfigFile(cmd, args[:1], toComplete)
+	given := make(map[string]bool)
+	for _, arg := range args[1:] {
+		given[arg] = true
+	}
+
+	var remaining []string
+	for _, completion := range completions {
+		if !given[strings.SplitN(completion, "\t", 2)[0]] {
+			remaining = append(remaining, completion)
+		}
+	}
+	return remaining, directive
+}
+
 func init() {
-	configCmd.Flags().StringVar(&previewHighlightStyle, "preview-highlight", "monokai", "Syntax highlight style for preview (e.g. monokai, dracula, solarized-dark, etc)")
+	configCmd.Flags().StringVar(&previewHighlightStyle, "preview-highlight", "monokai", "Syntax highlight style for preview (e.g. monokai, dracula, solarized-dark, etc), overriding the one saved from the TUI")
+	configCmd.AddCommand(configValidateCmd)
+	configEditCmd.Flags().BoolVar(&configEditLast, "last", false, "Reopen the most recently edited file")
+	configCmd.AddCommand(configEditCmd)
+
+	configListCmd.Flags().BoolVarP(&configJSON, "json", "j", false, "Output in JSON format")
+	configPathCmd.Flags().BoolVarP(&configJSON, "json", "j", false, "Output in JSON format")
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+
+	configHistoryCmd.Flags().BoolVarP(&configJSON, "json", "j", false, "Output in JSON format")
+	configCmd.AddCommand(configHistoryCmd)
+	configCmd.AddCommand(configRestoreCmd)
+
+	configGrepCmd.Flags().BoolVarP(&configJSON, "json", "j", false, "Output in JSON format")
+	configCmd.AddCommand(configGrepCmd)
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -37,24 +452,30 @@ func runConfigCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	debug, _ := cmd.Flags().GetBool("debug")
-	model := tui.NewModel(registry, previewHighlightStyle, debug)
-
-	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	finalModel, err := p.Run()
+	settings, err := config.LoadSettings()
 	if err != nil {
-		fmt.Printf("Error running TUI: %v\n", err)
+		fmt.Printf("Error loading settings: %v\n", err)
+		return
+	}
+	keys, err := tui.NewKeyMap(settings.Keys)
+	if err != nil {
+		fmt.Printf("Error in %s:\n", config.SettingsPath())
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
 		return
 	}
 
-	if m, ok := finalModel.(*tui.Model); ok && !m.IsQuitting() {
-		selectedApp := m.GetSelectedApp()
-		selectedFile := m.GetSelectedFile()
+	highlightStyle := previewHighlightStyle
+	if !cmd.Flags().Changed("preview-highlight") && settings.HighlightStyle != "" {
+		highlightStyle = settings.HighlightStyle
+	}
 
-		if selectedApp != "" && selectedFile != "" {
-			appConfig := registry.Apps[selectedApp]
-			fileConfig := appConfig.Files[selectedFile]
-			config.EditConfigFile(selectedApp, selectedFile, fileConfig)
-		}
+	debug, _ := cmd.Flags().GetBool("debug")
+	model := tui.NewModel(registry, keys, tui.LoadTheme(settings.Monochrome), highlightStyle, debug)
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		fmt.Printf("Error running TUI: %v\n", err)
 	}
 }
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBackupRetention is the number of snapshots kept per config file
+// when backup_retention is not set.
+const DefaultBackupRetention = 20
+
+const backupTimeFormat = "20060102-150405.000"
+
+type Backup struct {
+	ID   string    `json:"id"`
+	App  string    `json:"app"`
+	File string    `json:"file"`
+	Path string    `json:"path"`
+	Time time.Time `json:"time"`
+	Size int64     `json:"size"`
+}
+
+// StateDir returns hydectl's directory under $XDG_STATE_HOME.
+func StateDir() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = filepath.Join(os.Getenv("HOME"), ".local", "state")
+	}
+	return filepath.Join(stateHome, "hydectl")
+}
+
+func backupDir(appName, fileName string) string {
+	clean := func(s string) string {
+		return strings.ReplaceAll(s, string(os.PathSeparator), "_")
+	}
+	return filepath.Join(StateDir(), "backups", clean(appName), clean(fileName))
+}
+
+// CreateBackup snapshots configPath into the backup store. Nothing is stored
+// when the file does not exist, and when it matches the latest snapshot that
+// snapshot is returned instead. created reports whether a new snapshot was
+// written.
+func CreateBackup(appName, fileName, configPath string) (backup *Backup, created bool, err error) {
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	backups, err := ListBackups(appName, fileName)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(backups) > 0 {
+		if latest, err := os.ReadFile(backups[0].Path); err == nil && bytes.Equal(latest, data) {
+			return &backups[0], false, nil
+		}
+	}
+
+	dir := backupDir(appName, fileName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	now := time.Now()
+	id, path, err := writeNewBackup(dir, now.Format(backupTimeFormat), data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := pruneBackups(appName, fileName, backupRetention()); err != nil {
+		return nil, false, err
+	}
+
+	return &Backup{ID: id, App: appName, File: fileName, Path: path, Time: now, Size: int64(len(data))}, true, nil
+}
+
+// writeNewBackup writes data to a snapshot file that did not exist yet. When
+// another snapshot was taken in the same millisecond, the ID gets a "-N"
+// suffix.
+func writeNewBackup(dir, stamp string, data []byte) (id, path string, err error) {
+	for n := 1; ; n++ {
+		id = stamp
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", stamp, n)
+		}
+		path = filepath.Join(dir, id+".bak")
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return "", "", fmt.Errorf("failed to write backup: %w", err)
+		}
+		return id, path, nil
+	}
+}
+
+// parseBackupID returns the time a snapshot was taken and its position among
+// the snapshots of the same millisecond.
+func parseBackupID(id string) (time.Time, int, error) {
+	stamp, seq := id, 1
+	if len(id) > len(backupTimeFormat) {
+		stamp = id[:len(backupTimeFormat)]
+		suffix, ok := strings.CutPrefix(id[len(backupTimeFormat):], "-")
+		n, err := strconv.Atoi(suffix)
+		if !ok || err != nil {
+			return time.Time{}, 0, fmt.Errorf("invalid backup ID %q", id)
+		}
+		seq = n
+	}
+	t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+	return t, seq, err
+}
+
+// ListBackups returns the snapshots of a config file, newest first.
+func ListBackups(appName, fileName string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupDir(appName, fileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []Backup
+	seqs := make(map[string]int)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".bak") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".bak")
+		t, seq, err := parseBackupID(id)
+		if err != nil {
+			continue
+		}
+		seqs[id] = seq
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{
+			ID:   id,
+			App:  appName,
+			File: fileName,
+			Path: filepath.Join(backupDir(appName, fileName), name),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return seqs[backups[i].ID] > seqs[backups[j].ID]
+	})
+
+	return backups, nil
+}
+
+// FindBackup looks a snapshot up by ID or by its 1-based position in
+// ListBackups. An empty ref selects the latest snapshot.
+func FindBackup(appName, fileName, ref string) (*Backup, error) {
+	backups, err := ListBackups(appName, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, fmt.Errorf("no backups found for %s/%s", appName, fileName)
+	}
+	if ref == "" {
+		return &backups[0], nil
+	}
+
+	if index, err := strconv.Atoi(ref); err == nil {
+		if index < 1 || index > len(backups) {
+			return nil, fmt.Errorf("backup #%d out of range, %s/%s has %d backup(s)", index, appName, fileName, len(backups))
+		}
+		return &backups[index-1], nil
+	}
+
+	for i := range backups {
+		if backups[i].ID == ref {
+			return &backups[i], nil
+		}
+	}
+	return nil, fmt.Errorf("backup %q not found for %s/%s", ref, appName, fileName)
+}
+
+// RestoreBackup writes a snapshot back to configPath. The current contents
+// are snapshotted first so the restore itself can be undone.
+func RestoreBackup(backup Backup, configPath string) error {
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if _, _, err := CreateBackup(backup.App, backup.File, configPath); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(configPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, perm); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// backupRetention returns the number of snapshots to keep per file.
+func backupRetention() int {
+	settings, err := LoadSettings()
+	if err != nil || settings.BackupRetention <= 0 {
+		return DefaultBackupRetention
+	}
+	return settings.BackupRetention
+}
+
+func pruneBackups(appName, fileName string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	backups, err := ListBackups(appName, fileName)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].Path); err != nil {
+			return fmt.Errorf("failed to prune backup: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine is one line of a line based diff. OldLine and NewLine are 1-based
+// and zero when the line does not exist on that side.
+type DiffLine struct {
+	Op      DiffOp
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// maxDiffEdits bounds the Myers search. Inputs needing more edits are
+// reported as a full replacement instead.
+const maxDiffEdits = 1000
+
+// SplitLines splits text into lines without a trailing empty line.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// DiffLines computes a minimal line diff between a and b.
+func DiffLines(a, b []string) []DiffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out []DiffLine
+	for i := 0; i < prefix; i++ {
+		out = append(out, DiffLine{Op: DiffEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	for _, d := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if d.OldLine > 0 {
+			d.OldLine += prefix
+		}
+		if d.NewLine > 0 {
+			d.NewLine += prefix
+		}
+		out = append(out, d)
+	}
+
+	for i := 0; i < suffix; i++ {
+		oi, ni := len(a)-suffix+i, len(b)-suffix+i
+		out = append(out, DiffLine{Op: DiffEqual, Text: a[oi], OldLine: oi + 1, NewLine: ni + 1})
+	}
+
+	return out
+}
+
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxDiffEdits {
+		maxD = maxDiffEdits
+	}
+
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		var out []DiffLine
+		for i, line := range a {
+			out = append(out, DiffLine{Op: DiffDelete, Text: line, OldLine: i + 1})
+		}
+		for i, line := range b {
+			out = append(out, DiffLine{Op: DiffInsert, Text: line, NewLine: i + 1})
+		}
+		return out
+	}
+
+	// Walk the trace backwards to recover the edit script.
+	var rev []DiffLine
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			rev = append(rev, DiffLine{Op: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+			x--
+			y--
+		}
+		if x == prevX {
+			rev = append(rev, DiffLine{Op: DiffInsert, Text: b[y-1], NewLine: y})
+		} else {
+			rev = append(rev, DiffLine{Op: DiffDelete, Text: a[x-1], OldLine: x})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		rev = append(rev, DiffLine{Op: DiffEqual, Text: a[x-1], OldLine: x, NewLine: y})
+		x--
+		y--
+	}
+
+	out := make([]DiffLine, len(rev))
+	for i := range rev {
+		out[i] = rev[len(rev)-1-i]
+	}
+	return out
+}
+
+// UnifiedDiffHeaderLines is the number of file header lines UnifiedDiff
+// starts with. Content lines after them may begin with "---" or "+++" too.
+const UnifiedDiffHeaderLines = 2
+
+// UnifiedDiff renders the difference between oldText and newText in unified
+// format with the given number of context lines. It returns an empty string
+// when both are equal.
+func UnifiedDiff(oldName, newName, oldText, newText string, context int) string {
+	lines := DiffLines(SplitLines(oldText), SplitLines(newText))
+
+	var changes []int
+	for i, l := range lines {
+		if l.Op != DiffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(changes); {
+		start := max(changes[i]-context, 0)
+		end := changes[i]
+		for i < len(changes) && changes[i] <= end+2*context {
+			end = changes[i]
+			i++
+		}
+		end = min(end+context, len(lines)-1)
+
+		hunk := lines[start : end+1]
+		oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+		for _, l := range hunk {
+			if l.Op != DiffInsert {
+				if oldStart == 0 {
+					oldStart = l.OldLine
+				}
+				oldCount++
+			}
+			if l.Op != DiffDelete {
+				if newStart == 0 {
+					newStart = l.NewLine
+				}
+				newCount++
+			}
+		}
+		if oldStart == 0 {
+			oldStart = hunkAnchor(lines, start, func(l DiffLine) int { return l.OldLine })
+		}
+		if newStart == 0 {
+			newStart = hunkAnchor(lines, start, func(l DiffLine) int { return l.NewLine })
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range hunk {
+			switch l.Op {
+			case DiffEqual:
+				b.WriteString(" " + l.Text + "\n")
+			case DiffDelete:
+				b.WriteString("-" + l.Text + "\n")
+			case DiffInsert:
+				b.WriteString("+" + l.Text + "\n")
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// hunkAnchor returns the line number preceding a hunk that has no lines on
+// one side, as unified diff expects for empty ranges.
+func hunkAnchor(lines []DiffLine, start int, lineOf func(DiffLine) int) int {
+	for i := start - 1; i >= 0; i-- {
+		if n := lineOf(lines[i]); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Versions of a config file that its current contents can be diffed against.
+const (
+	DiffBaseBackup  = "backup"
+	DiffBaseDefault = "default"
+	DiffBaseGit     = "git"
+)
+
+// DiffBases lists the diff bases in the order the TUI cycles through them.
+var DiffBases = []string{DiffBaseBackup, DiffBaseDefault, DiffBaseGit}
+
+// DefaultPath returns the expanded path of the file's default copy, or an
+// empty string when the registry declares none.
+func (c *ConfigFile) DefaultPath() string {
+	if c.Default == "" {
+		return ""
+	}
+	path := ExpandPath(c.Default)
+	if !filepath.IsAbs(path) && c.DefaultDir != "" {
+		path = filepath.Join(c.DefaultDir, path)
+	}
+	return path
+}
+
+// DiffBaseContent returns the contents of a config file as of the given diff
+// base, along with a label describing it.
+func DiffBaseContent(appName, fileName string, fileConfig ConfigFile, base string) (content, label string, err error) {
+	switch base {
+	case DiffBaseBackup:
+		backups, err := ListBackups(appName, fileName)
+		if err != nil {
+			return "", "", err
+		}
+		if len(backups) == 0 {
+			return "", "", fmt.Errorf("no backups of %s/%s yet", appName, fileName)
+		}
+		data, err := os.ReadFile(backups[0].Path)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read backup: %w", err)
+		}
+		return string(data), "backup " + backups[0].ID, nil
+
+	case DiffBaseDefault:
+		path := fileConfig.DefaultPath()
+		if path == "" {
+			return "", "", errors.New("the registry declares no default for this file")
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read default: %w", err)
+		}
+		return string(data), path, nil
+
+	case DiffBaseGit:
+		return gitHeadContent(ExpandPath(fileConfig.Path))
+	}
+	return "", "", fmt.Errorf("unknown diff base %q", base)
+}
+
+// DiffBaseVersion identifies the contents DiffBaseContent would return for
+// base without reading them: the latest backup's ID, the default file's path
+// and modification time, or the commit at HEAD. It is empty when the base is
+// not available.
+func DiffBaseVersion(appName, fileName string, fileConfig ConfigFile, base string) string {
+	switch base {
+	case DiffBaseBackup:
+		backups, err := ListBackups(appName, fileName)
+		if err != nil || len(backups) == 0 {
+			return ""
+		}
+		return backups[0].ID
+	case DiffBaseDefault:
+		path := fileConfig.DefaultPath()
+		info, err := os.Stat(path)
+		if path == "" || err != nil {
+			return ""
+		}
+		return fmt.Sprintf("%s@%d/%d", path, info.ModTime().UnixNano(), info.Size())
+	case DiffBaseGit:
+		path := ExpandPath(fileConfig.Path)
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		out, err := exec.Command("git", "-C", filepath.Dir(path), "rev-parse", "HEAD").Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	return ""
+}
+
+// gitHeadContent returns the file as committed at HEAD of the git repository
+// containing it. Symlinks are followed, so dotfiles linked into place from a
+// repository are found too.
+func gitHeadContent(path string) (string, string, error) {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	cmd := exec.Command("git", "-C", filepath.Dir(path), "show", "HEAD:./"+filepath.Base(path))
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", "", errors.New("file is not committed in a git repository")
+		}
+		return "", "", fmt.Errorf("failed to run git: %w", err)
+	}
+	return string(out), "git HEAD", nil
+}
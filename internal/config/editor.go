@@ -2,76 +2,534 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
+// Output receives the progress messages of an edit and the output of its
+// hooks. Callers that take over the terminal, like the TUI, may point it at
+// a writer that also records the output.
+var Output io.Writer = os.Stdout
+
+// Input is where confirmation prompts read their answer from.
+var Input io.Reader = os.Stdin
+
+// EditConfigFile opens a config file in the editor, surrounded by its pre
+// and post hooks. fileConfig should come from AppConfig.ResolveFile so the
+// app's default hooks apply.
 func EditConfigFile(appName, fileName string, fileConfig ConfigFile) {
-	fmt.Printf("\n🔧 Editing %s - %s\n", appName, fileConfig.Description)
-	fmt.Printf("📁 %s\n\n", fileConfig.Path)
+	EditConfigFileAt(appName, fileName, fileConfig, 0)
+}
+
+// EditConfigFileAt is EditConfigFile with the editor opened at line. A line
+// of zero leaves the cursor where the editor puts it.
+func EditConfigFileAt(appName, fileName string, fileConfig ConfigFile, line int) {
+	if _, _, ok := editConfigFile(appName, fileName, fileConfig, line); ok {
+		fmt.Fprintf(Output, "\n✅ Configuration editing completed for %s!\n", appName)
+	}
+}
+
+// EditConfigFiles edits several files of one app in a row. Hooks the files
+// inherit from the app run once: the pre-hook before the first file and the
+// post-hook after the last one, provided one of those files changed.
+func EditConfigFiles(appName string, appConfig AppConfig, fileNames []string) {
+	var sharedPre, sharedPost bool
+	env := HookEnv{App: appName}
+	for _, fileName := range fileNames {
+		if appConfig.InheritsPreHook(fileName) || appConfig.InheritsPostHook(fileName) {
+			env.Files = append(env.Files, fileName)
+			env.Paths = append(env.Paths, ExpandPath(appConfig.Files[fileName].Path))
+		}
+		sharedPre = sharedPre || appConfig.InheritsPreHook(fileName)
+		sharedPost = sharedPost || appConfig.InheritsPostHook(fileName)
+	}
+
+	if sharedPre {
+		fmt.Fprintf(Output, "\n⏳ Running %s pre-hook...\n", appName)
+		if err := runHook(appConfig.PreHook, env); err != nil {
+			fmt.Fprintf(Output, "⚠️  Pre-hook failed: %v\n", err)
+		}
+	}
+
+	runShared := false
+	var sharedEdits []fileEdit
+	for _, fileName := range fileNames {
+		fileConfig := appConfig.ResolveFile(fileName)
+		if appConfig.InheritsPreHook(fileName) {
+			fileConfig.PreHook = Hook{}
+		}
+		inheritedPost := appConfig.InheritsPostHook(fileName)
+		if inheritedPost {
+			fileConfig.PostHook = Hook{}
+		}
+
+		edit, changed, ok := editConfigFile(appName, fileName, fileConfig, 0)
+		if inheritedPost && ok {
+			sharedEdits = append(sharedEdits, edit)
+		}
+		if inheritedPost && (changed || fileConfig.alwaysRunsPostHook()) {
+			runShared = true
+		}
+		env.Changed = env.Changed || (inheritedPost && changed)
+	}
+
+	if sharedPost {
+		if runShared {
+			runPostHook(appName+" post-hook", appConfig.PostHook, sharedEdits, env)
+		} else {
+			fmt.Fprintf(Output, "\n⏭️  Skipping %s post-hook, no files changed\n", appName)
+		}
+	}
+
+	fmt.Fprintf(Output, "\n✅ Configuration editing completed for %s!\n", appName)
+}
+
+// fileEdit is an edit that went through, kept for the post-hook that follows
+// it in case the hook fails and the file has to be reopened or restored.
+type fileEdit struct {
+	fileName   string
+	configPath string
+	fileConfig ConfigFile
+	editor     editorCommand
+	// backup is the pre-edit snapshot, nil when the file did not exist
+	// before editing.
+	backup *Backup
+}
+
+// editConfigFile runs a single edit and reports whether the file changed and
+// whether the edit went through at all.
+func editConfigFile(appName, fileName string, fileConfig ConfigFile, line int) (edit fileEdit, changed bool, ok bool) {
+	fmt.Fprintf(Output, "\n🔧 Editing %s - %s\n", appName, fileConfig.Description)
+	fmt.Fprintf(Output, "📁 %s\n\n", fileConfig.Path)
 
 	configPath := ExpandPath(fileConfig.Path)
+	env := HookEnv{App: appName, Files: []string{fileName}, Paths: []string{configPath}}
 
-	if len(fileConfig.PreHook) > 0 {
-		fmt.Println("⏳ Running pre-hook...")
-		if err := runHook(fileConfig.PreHook); err != nil {
-			fmt.Printf("⚠️  Pre-hook failed: %v\n", err)
+	if !fileConfig.PreHook.IsZero() {
+		fmt.Fprintln(Output, "⏳ Running pre-hook...")
+		if err := runHook(fileConfig.PreHook, env); err != nil {
+			fmt.Fprintf(Output, "⚠️  Pre-hook failed: %v\n", err)
 		}
 	}
 
 	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
-		fmt.Printf("Error creating directory: %v\n", err)
-		return
+		fmt.Fprintf(Output, "Error creating directory: %v\n", err)
+		return edit, false, false
 	}
 
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
+	backup, created, err := CreateBackup(appName, fileName, configPath)
+	if err != nil {
+		fmt.Fprintf(Output, "⚠️  Backup failed: %v\n", err)
+	} else if created {
+		fmt.Fprintf(Output, "💾 Backup saved (%s)\n", backup.ID)
+	} else if backup != nil {
+		fmt.Fprintf(Output, "💾 Unchanged since backup %s\n", backup.ID)
+	}
 
-		editors := []string{"nvim", "vim", "nano", "code", "gedit"}
-		for _, e := range editors {
-			if _, err := exec.LookPath(e); err == nil {
-				editor = e
-				break
-			}
+	if !fileConfig.FileExists() && fileConfig.HasTemplate() {
+		if err := fileConfig.CreateFromTemplate(); err != nil {
+			fmt.Fprintf(Output, "⚠️  Creating from template failed: %v\n", err)
+		} else {
+			fmt.Fprintln(Output, "📄 Created from template")
 		}
 	}
 
-	if editor == "" {
-		fmt.Println("No editor found. Please set the EDITOR environment variable.")
-		return
+	// Read after seeding from the template, so the diff and the unchanged
+	// check compare against what the editor was given.
+	original, _ := os.ReadFile(configPath)
+
+	editor := findEditor(fileConfig)
+	if len(editor.args) == 0 {
+		fmt.Fprintf(Output, "No editor found. Please set VISUAL or EDITOR, or editor in %s.\n", SettingsPath())
+		return edit, false, false
 	}
 
-	fmt.Printf("🚀 Opening %s...\n", editor)
-	cmd := exec.Command(editor, configPath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	if !editUntilValid(editor, configPath, fileConfig.Format, line) {
+		if !fileConfig.PostHook.IsZero() {
+			fmt.Fprintln(Output, "\n⏭️  Skipping post-hook")
+		}
+		return edit, false, false
+	}
 
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("Error running editor: %v\n", err)
-		return
+	if err := RecordEdit(appName, fileName); err != nil {
+		fmt.Fprintf(Output, "⚠️  Failed to remember recent file: %v\n", err)
 	}
 
-	if len(fileConfig.PostHook) > 0 {
-		fmt.Println("\n⏳ Running post-hook...")
-		if err := runHook(fileConfig.PostHook); err != nil {
-			fmt.Printf("⚠️  Post-hook failed: %v\n", err)
+	edited, _ := os.ReadFile(configPath)
+	changed = printDiffSummary(configPath, string(original), string(edited))
+	env.Changed = changed
+	edit = fileEdit{fileName: fileName, configPath: configPath, fileConfig: fileConfig, editor: editor, backup: backup}
+
+	if !fileConfig.PostHook.IsZero() {
+		if changed || fileConfig.alwaysRunsPostHook() {
+			runPostHook("post-hook", fileConfig.PostHook, []fileEdit{edit}, env)
 		} else {
-			fmt.Println("✅ Post-hook completed successfully")
+			fmt.Fprintln(Output, "⏭️  Skipping post-hook, file is unchanged")
 		}
 	}
 
-	fmt.Printf("\n✅ Configuration editing completed for %s!\n", appName)
+	return edit, changed, true
+}
+
+// editorCommand is the resolved editor: the program with its own arguments
+// and the template used to open it at a line when hydectl doesn't know it.
+type editorCommand struct {
+	args     []string
+	lineArgs string
 }
 
-func runHook(hook []string) error {
-	if len(hook) == 0 {
-		return nil
+func (e editorCommand) String() string {
+	return strings.Join(e.args, " ")
+}
+
+// guiEditors return immediately unless told to wait for the file to close.
+var guiEditors = map[string]bool{"code": true, "codium": true, "code-oss": true, "subl": true, "zed": true, "gedit": true}
+
+// findEditor picks the editor for a file: the registry's per-file or per-app
+// editor, the editor setting in hydectl's config file, $VISUAL, $EDITOR and
+// finally the first of a few common editors found in PATH. It returns a zero
+// editorCommand when none is available.
+func findEditor(fileConfig ConfigFile) editorCommand {
+	settings, err := LoadSettings()
+	if err != nil {
+		fmt.Fprintf(Output, "⚠️  %v\n", err)
+	}
+
+	lineArgs := os.Getenv(EditorLineArgsEnv)
+	if lineArgs == "" {
+		lineArgs = settings.EditorLineArgs
 	}
 
-	cmd := exec.Command(hook[0], hook[1:]...)
+	for _, candidate := range []string{fileConfig.Editor, settings.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if args := splitCommand(candidate); len(args) > 0 {
+			return editorCommand{args: waitForEditor(args), lineArgs: lineArgs}
+		}
+	}
+
+	for _, e := range []string{"nvim", "vim", "nano", "code", "gedit"} {
+		if _, err := exec.LookPath(e); err == nil {
+			return editorCommand{args: waitForEditor([]string{e}), lineArgs: lineArgs}
+		}
+	}
+	return editorCommand{}
+}
+
+// waitForEditor adds --wait to GUI editors so the post-hook runs only after
+// the file is closed.
+func waitForEditor(args []string) []string {
+	if !guiEditors[filepath.Base(args[0])] {
+		return args
+	}
+	for _, arg := range args[1:] {
+		if arg == "--wait" || arg == "-w" {
+			return args
+		}
+	}
+	return append(args, "--wait")
+}
+
+// splitCommand splits a command line on whitespace, keeping single or double
+// quoted parts together.
+func splitCommand(command string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// openEditor runs the editor on configPath, positioned at line when it is
+// greater than zero.
+func openEditor(editor editorCommand, configPath string, line int) error {
+	fmt.Fprintf(Output, "🚀 Opening %s...\n", editor)
+	args := append(append([]string(nil), editor.args[1:]...), editorArgs(editor, configPath, line)...)
+	cmd := exec.Command(editor.args[0], args...)
+	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
+
+// EditorLineArgsEnv names the variable holding the argument template used to
+// open editors hydectl doesn't know at a line, e.g. "--line {line} {file}".
+// It takes precedence over editor_line_args in hydectl's config file.
+const EditorLineArgsEnv = "HYDECTL_EDITOR_LINE_ARGS"
+
+func editorArgs(editor editorCommand, configPath string, line int) []string {
+	if line <= 0 {
+		return []string{configPath}
+	}
+
+	switch filepath.Base(editor.args[0]) {
+	case "vi", "vim", "nvim", "nano", "emacs", "emacsclient", "micro", "kak", "gedit":
+		return []string{fmt.Sprintf("+%d", line), configPath}
+	case "code", "codium", "code-oss":
+		return []string{"--goto", fmt.Sprintf("%s:%d", configPath, line)}
+	case "hx", "helix":
+		return []string{fmt.Sprintf("%s:%d", configPath, line)}
+	}
+
+	if editor.lineArgs != "" {
+		return expandLineArgs(editor.lineArgs, configPath, line)
+	}
+	// Other editors may take "+N" for a file name, so they just open the file.
+	return []string{configPath}
+}
+
+// expandLineArgs splits an argument template on whitespace and substitutes
+// {file} and {line} in each argument.
+func expandLineArgs(template, configPath string, line int) []string {
+	replacer := strings.NewReplacer("{file}", configPath, "{line}", fmt.Sprint(line))
+	var args []string
+	for _, field := range strings.Fields(template) {
+		args = append(args, replacer.Replace(field))
+	}
+	return args
+}
+
+// editUntilValid opens the editor and checks the file's syntax afterwards,
+// offering to reopen the editor at the failing line until the file parses.
+// It reports false when the editor failed or the user gave up on an invalid
+// file, in which case the post-hook must not run.
+func editUntilValid(editor editorCommand, configPath, format string, line int) bool {
+	for {
+		if err := openEditor(editor, configPath, line); err != nil {
+			fmt.Fprintf(Output, "Error running editor: %v\n", err)
+			return false
+		}
+
+		syntaxErr := CheckSyntax(format, configPath)
+		if syntaxErr == nil {
+			return true
+		}
+
+		fmt.Fprintf(Output, "\n❌ Syntax error: %v\n", syntaxErr)
+		if text := lineText(configPath, syntaxErr.Line); text != "" {
+			fmt.Fprintf(Output, "%5d │ %s\n", syntaxErr.Line, text)
+		}
+
+		question := "Reopen the editor?"
+		if syntaxErr.Line > 0 {
+			question = fmt.Sprintf("Reopen the editor at line %d?", syntaxErr.Line)
+		}
+		if !confirm(question) {
+			return false
+		}
+		line = syntaxErr.Line
+	}
+}
+
+func lineText(path string, line int) string {
+	if line <= 0 {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(string(data), "\n")
+	if line > len(lines) {
+		return ""
+	}
+	return lines[line-1]
+}
+
+// maxDiffSummaryLines caps the diff printed after editing.
+const maxDiffSummaryLines = 200
+
+// printDiffSummary prints a colored unified diff of the edit and reports
+// whether the file changed.
+func printDiffSummary(configPath, original, edited string) bool {
+	diff := UnifiedDiff(configPath+" (before)", configPath, original, edited, 3)
+	if diff == "" {
+		fmt.Fprintln(Output, "\n📭 No changes")
+		return false
+	}
+
+	const (
+		red   = "\033[31m"
+		green = "\033[32m"
+		cyan  = "\033[36m"
+		bold  = "\033[1m"
+		reset = "\033[0m"
+	)
+	color := func(code, line string) string {
+		if os.Getenv("NO_COLOR") != "" {
+			return line
+		}
+		return code + line + reset
+	}
+
+	fmt.Fprintln(Output, "\n📝 Changes:")
+	lines := SplitLines(diff)
+	for i, line := range lines {
+		if i == maxDiffSummaryLines {
+			fmt.Fprintf(Output, "... %d more lines\n", len(lines)-i)
+			break
+		}
+		switch {
+		case i < UnifiedDiffHeaderLines:
+			fmt.Fprintln(Output, color(bold, line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprintln(Output, color(cyan, line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprintln(Output, color(red, line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprintln(Output, color(green, line))
+		default:
+			fmt.Fprintln(Output, line)
+		}
+	}
+	return true
+}
+
+// runPostHook runs a post-hook for the edits and applies their on_failure
+// policies when it exits non-zero: files set to prompt are offered to be
+// reopened, after which the hook runs again, and files set to restore get
+// their pre-edit copy back before the hook is re-run once.
+func runPostHook(name string, hook Hook, edits []fileEdit, env HookEnv) {
+	for {
+		fmt.Fprintf(Output, "\n⏳ Running %s...\n", name)
+		err := runHook(hook, env)
+		if err == nil {
+			fmt.Fprintln(Output, "✅ Post-hook completed successfully")
+			return
+		}
+		fmt.Fprintf(Output, "⚠️  Post-hook failed: %v\n", err)
+
+		var prompt, restore []fileEdit
+		for _, edit := range edits {
+			switch edit.fileConfig.OnFailure {
+			case OnFailurePrompt:
+				prompt = append(prompt, edit)
+			case OnFailureRestore:
+				restore = append(restore, edit)
+			}
+		}
+
+		if len(prompt) > 0 && confirm("Reopen the editor to fix it?") {
+			for _, edit := range prompt {
+				if !editUntilValid(edit.editor, edit.configPath, edit.fileConfig.Format, 0) {
+					return
+				}
+			}
+			continue
+		}
+
+		if len(restore) == 0 {
+			return
+		}
+		for _, edit := range restore {
+			if err := restorePreEdit(env.App, edit.fileName, edit.configPath, edit.backup); err != nil {
+				fmt.Fprintf(Output, "⚠️  Restoring pre-edit copy of %s failed: %v\n", edit.fileName, err)
+				return
+			}
+		}
+		fmt.Fprintln(Output, "⏪ Restored the pre-edit copy, your edit was kept as a backup")
+
+		fmt.Fprintf(Output, "\n⏳ Re-running %s...\n", name)
+		if err := runHook(hook, env); err != nil {
+			fmt.Fprintf(Output, "⚠️  Post-hook failed: %v\n", err)
+		} else {
+			fmt.Fprintln(Output, "✅ Post-hook completed successfully")
+		}
+		return
+	}
+}
+
+// restorePreEdit puts back the pre-edit snapshot, or removes the file when it
+// did not exist before editing. The failed edit is backed up either way.
+func restorePreEdit(appName, fileName, configPath string, backup *Backup) error {
+	if backup != nil {
+		return RestoreBackup(*backup, configPath)
+	}
+
+	if _, _, err := CreateBackup(appName, fileName, configPath); err != nil {
+		return err
+	}
+	if err := os.Remove(configPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func confirm(question string) bool {
+	fmt.Fprintf(Output, "%s [Y/n] ", question)
+	answer, err := readLine(Input)
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// readLine reads up to and including the next newline one byte at a time.
+// It does not buffer past the newline, so input typed after the answer is
+// left for whoever reads the terminal next, like the TUI on resume.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// RestoreConfigFile rolls a config file back to a snapshot and runs its
+// post-hook so the application picks the restored contents up.
+func RestoreConfigFile(appName, fileName string, fileConfig ConfigFile, backup Backup) error {
+	configPath := ExpandPath(fileConfig.Path)
+
+	if err := RestoreBackup(backup, configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(Output, "⏪ Restored %s - %s from backup %s\n", appName, fileName, backup.ID)
+
+	if !fileConfig.PostHook.IsZero() {
+		env := HookEnv{App: appName, Files: []string{fileName}, Paths: []string{configPath}, Changed: true}
+		fmt.Fprintln(Output, "\n⏳ Running post-hook...")
+		if err := runHook(fileConfig.PostHook, env); err != nil {
+			fmt.Fprintf(Output, "⚠️  Post-hook failed: %v\n", err)
+		} else {
+			fmt.Fprintln(Output, "✅ Post-hook completed successfully")
+		}
+	}
+
+	return nil
+}
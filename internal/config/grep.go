@@ -0,0 +1,69 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// maxGrepLineLength bounds the lines scanned by Grep. Scanning a file stops
+// at the first longer line.
+const maxGrepLineLength = 1024 * 1024
+
+type GrepMatch struct {
+	App  string `json:"app"`
+	File string `json:"file"`
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// CompileGrepPattern compiles a case-insensitive search pattern. Patterns
+// that are not valid regular expressions are matched literally.
+func CompileGrepPattern(pattern string) *regexp.Regexp {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(pattern))
+	}
+	return re
+}
+
+// Grep searches the contents of every registered file, in registry order,
+// and returns the matching lines. Missing and binary files are skipped.
+func (r *OrderedConfigRegistry) Grep(re *regexp.Regexp) []GrepMatch {
+	var matches []GrepMatch
+	for _, appName := range r.AppsOrder {
+		app := r.Apps[appName]
+		for _, fileName := range app.FileNames() {
+			path := ExpandPath(app.Files[fileName].Path)
+			for _, hit := range grepFile(path, re) {
+				hit.App = appName
+				hit.File = fileName
+				matches = append(matches, hit)
+			}
+		}
+	}
+	return matches
+}
+
+func grepFile(path string, re *regexp.Regexp) []GrepMatch {
+	data, err := os.ReadFile(path)
+	if err != nil || bytes.IndexByte(data, 0) >= 0 {
+		return nil
+	}
+
+	var matches []GrepMatch
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), maxGrepLineLength)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if re.MatchString(line) {
+			matches = append(matches, GrepMatch{Path: path, Line: lineNum, Text: strings.TrimRight(line, "\r")})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultHookTimeout applies to hooks that do not set a timeout.
+var DefaultHookTimeout = 30 * time.Second
+
+// Hook is a command run around an edit. In the registry it is written as an
+// argument list, as a shell string, or as a table:
+//
+//	post_hook = ["killall", "-SIGUSR1", "kitty"]
+//	post_hook = "killall dunst && dunst & disown"
+//	post_hook = { shell = "killall dunst && dunst & disown", timeout = "5s" }
+//	post_hook = { command = ["waybar-reload"], timeout = "10s" }
+type Hook struct {
+	Command []string
+	Shell   string
+	Timeout time.Duration
+}
+
+func (h *Hook) UnmarshalTOML(data interface{}) error {
+	switch v := data.(type) {
+	case string:
+		h.Shell = v
+		return nil
+	case []interface{}:
+		command, err := toStringSlice(v)
+		if err != nil {
+			return err
+		}
+		h.Command = command
+		return nil
+	case map[string]interface{}:
+		for key, value := range v {
+			switch key {
+			case "command":
+				list, ok := value.([]interface{})
+				if !ok {
+					return fmt.Errorf("hook command must be an array of strings")
+				}
+				command, err := toStringSlice(list)
+				if err != nil {
+					return err
+				}
+				h.Command = command
+			case "shell":
+				shell, ok := value.(string)
+				if !ok {
+					return fmt.Errorf("hook shell must be a string")
+				}
+				h.Shell = shell
+			case "timeout":
+				timeout, ok := value.(string)
+				if !ok {
+					return fmt.Errorf("hook timeout must be a duration string such as \"10s\"")
+				}
+				d, err := time.ParseDuration(timeout)
+				if err != nil {
+					return fmt.Errorf("invalid hook timeout %q: %w", timeout, err)
+				}
+				h.Timeout = d
+			default:
+				return fmt.Errorf("unknown hook key %q, expected command, shell or timeout", key)
+			}
+		}
+		if len(h.Command) > 0 && h.Shell != "" {
+			return fmt.Errorf("hook cannot set both command and shell")
+		}
+		if h.IsZero() {
+			return fmt.Errorf("hook table needs a command or a shell string")
+		}
+		return nil
+	default:
+		return fmt.Errorf("hook must be an array, a string or a table, got %T", data)
+	}
+}
+
+func toStringSlice(list []interface{}) ([]string, error) {
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("hook command must be an array of strings")
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// IsZero reports whether the hook has nothing to run.
+func (h Hook) IsZero() bool {
+	return len(h.Command) == 0 && strings.TrimSpace(h.Shell) == ""
+}
+
+// Executable returns the program the hook starts. For shell hooks it is the
+// first word of the shell string, or empty when that is a shell builtin or
+// keyword or uses shell syntax, so only the shell knows what runs.
+func (h Hook) Executable() string {
+	if h.Shell != "" {
+		return shellExecutable(h.Shell)
+	}
+	if len(h.Command) == 0 {
+		return ""
+	}
+	return h.Command[0]
+}
+
+// shellBuiltins are words a shell string may start with that are not looked
+// up in PATH.
+var shellBuiltins = map[string]bool{
+	".": true, ":": true, "[": true, "alias": true, "builtin": true, "case": true, "cd": true,
+	"command": true, "declare": true, "echo": true, "eval": true, "exec": true, "exit": true,
+	"export": true, "false": true, "for": true, "function": true, "if": true, "local": true,
+	"printf": true, "read": true, "return": true, "set": true, "shift": true, "source": true,
+	"test": true, "trap": true, "true": true, "type": true, "unset": true, "until": true,
+	"wait": true, "while": true,
+}
+
+// shellMetachars mark a first word that the shell expands or parses.
+const shellMetachars = "$`=(){}<>|&;*?[]~!\\\"'"
+
+func shellExecutable(script string) string {
+	fields := strings.Fields(script)
+	if len(fields) == 0 {
+		return ""
+	}
+	first := fields[0]
+	if shellBuiltins[first] || strings.ContainsAny(first, shellMetachars) {
+		return ""
+	}
+	return first
+}
+
+// HookShell returns the shell that runs shell string hooks: bash when it is
+// installed, as HyDE's own scripts assume it, and sh otherwise.
+func HookShell() string {
+	if path, err := exec.LookPath("bash"); err == nil {
+		return path
+	}
+	return "sh"
+}
+
+func (h Hook) String() string {
+	if h.Shell != "" {
+		return h.Shell
+	}
+	return strings.Join(h.Command, " ")
+}
+
+// HookEnv describes the edit a hook runs for. It is exported to the hook as
+// HYDECTL_APP, HYDECTL_FILE, HYDECTL_PATH and HYDECTL_CHANGED. Hooks shared
+// by several files get the file names space separated and the paths colon
+// separated.
+type HookEnv struct {
+	App     string
+	Files   []string
+	Paths   []string
+	Changed bool
+}
+
+func (e HookEnv) environ() []string {
+	changed := "0"
+	if e.Changed {
+		changed = "1"
+	}
+	return append(os.Environ(),
+		"HYDECTL_APP="+e.App,
+		"HYDECTL_FILE="+strings.Join(e.Files, " "),
+		"HYDECTL_PATH="+strings.Join(e.Paths, ":"),
+		"HYDECTL_CHANGED="+changed,
+	)
+}
+
+func runHook(hook Hook, env HookEnv) error {
+	if hook.IsZero() {
+		return nil
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if hook.Shell != "" {
+		cmd = exec.CommandContext(ctx, HookShell(), "-c", hook.Shell)
+	} else {
+		cmd = exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	}
+	cmd.Env = env.environ()
+
+	out, flush, err := hookOutput()
+	if err != nil {
+		return err
+	}
+	defer flush()
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	// A hook with its own timeout runs in its own process group, so timing
+	// out also stops the children it spawned. Under the default timeout only
+	// the hook itself is killed, sparing daemons it started in the background.
+	if hook.Timeout > 0 {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		cmd.Cancel = func() error {
+			return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		}
+	}
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// hookOutput returns the file hooks write to. Hooks get a real file rather
+// than a pipe, so daemons they leave running neither hold up the edit nor die
+// of SIGPIPE once it is done. When Output is not a file, the hook writes to a
+// temporary file that flush copies to Output.
+func hookOutput() (*os.File, func(), error) {
+	if f, ok := Output.(*os.File); ok {
+		return f, func() {}, nil
+	}
+	tmp, err := os.CreateTemp("", "hydectl-hook-*.log")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create hook output file: %w", err)
+	}
+	flush := func() {
+		if _, err := tmp.Seek(0, io.SeekStart); err == nil {
+			io.Copy(Output, tmp)
+		}
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	return tmp, flush, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindApp resolves a possibly abbreviated app name against the registry.
+func (r *OrderedConfigRegistry) FindApp(query string) (string, error) {
+	name, err := matchName(query, r.AppsOrder)
+	if err != nil {
+		return "", fmt.Errorf("app %w", err)
+	}
+	return name, nil
+}
+
+// FindFile resolves a possibly abbreviated file name of an app. An empty
+// query is accepted when the app has a single file.
+func (r *OrderedConfigRegistry) FindFile(appName, query string) (string, error) {
+	app, ok := r.Apps[appName]
+	if !ok {
+		return "", fmt.Errorf("app %q not found in config registry", appName)
+	}
+
+	files := app.FileNames()
+	if query == "" {
+		if len(files) == 1 {
+			return files[0], nil
+		}
+		return "", fmt.Errorf("app %q has several files, choose one of: %s", appName, strings.Join(files, ", "))
+	}
+
+	name, err := matchName(query, files)
+	if err != nil {
+		return "", fmt.Errorf("file %w", err)
+	}
+	return name, nil
+}
+
+// matchName picks the candidate matching query, trying an exact match, then
+// a prefix, a substring and finally an in-order subsequence of characters.
+// It fails when nothing matches or a stage matches more than one candidate.
+func matchName(query string, candidates []string) (string, error) {
+	q := strings.ToLower(query)
+
+	stages := []func(name string) bool{
+		func(name string) bool { return name == q },
+		func(name string) bool { return strings.HasPrefix(name, q) },
+		func(name string) bool { return strings.Contains(name, q) },
+		func(name string) bool { return isSubsequence(q, name) },
+	}
+
+	for _, matches := range stages {
+		var found []string
+		for _, candidate := range candidates {
+			if matches(strings.ToLower(candidate)) {
+				found = append(found, candidate)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			return "", fmt.Errorf("%q is ambiguous: %s", query, strings.Join(found, ", "))
+		}
+	}
+
+	return "", fmt.Errorf("%q not found, available: %s", query, strings.Join(candidates, ", "))
+}
+
+func isSubsequence(needle, haystack string) bool {
+	h := []rune(haystack)
+	i := 0
+	for _, r := range needle {
+		for i < len(h) && h[i] != r {
+			i++
+		}
+		if i == len(h) {
+			return false
+		}
+		i++
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxRecentFiles is the number of edited files remembered.
+var MaxRecentFiles = 10
+
+// FileRef names a registered config file.
+type FileRef struct {
+	App  string `json:"app"`
+	File string `json:"file"`
+}
+
+// RecentEdit is a file and when it was last edited.
+type RecentEdit struct {
+	FileRef
+	Time time.Time `json:"time"`
+}
+
+// RecentFiles are the files edited most recently, newest first, and the files
+// pinned for quick access in the TUI.
+type RecentFiles struct {
+	Recent []RecentEdit `json:"recent"`
+	Pinned []FileRef    `json:"pinned"`
+}
+
+// RecentFilesPath returns where RecentFiles are stored.
+func RecentFilesPath() string {
+	return filepath.Join(StateDir(), "recent.json")
+}
+
+// LoadRecentFiles reads the recent and pinned files. A missing file yields
+// empty lists.
+func LoadRecentFiles() (RecentFiles, error) {
+	var recent RecentFiles
+	path := RecentFilesPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return recent, nil
+	}
+	if err != nil {
+		return recent, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return RecentFiles{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return recent, nil
+}
+
+// Save writes the recent and pinned files to RecentFilesPath.
+func (r *RecentFiles) Save() error {
+	path := RecentFilesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Last returns the most recently edited file.
+func (r *RecentFiles) Last() (FileRef, bool) {
+	if len(r.Recent) == 0 {
+		return FileRef{}, false
+	}
+	return r.Recent[0].FileRef, true
+}
+
+// IsPinned reports whether the file is pinned.
+func (r *RecentFiles) IsPinned(appName, fileName string) bool {
+	return indexOfRef(r.Pinned, appName, fileName) >= 0
+}
+
+// TogglePin pins the file, or unpins it when it already is. It reports
+// whether the file is pinned afterwards.
+func (r *RecentFiles) TogglePin(appName, fileName string) bool {
+	if i := indexOfRef(r.Pinned, appName, fileName); i >= 0 {
+		r.Pinned = append(r.Pinned[:i], r.Pinned[i+1:]...)
+		return false
+	}
+	r.Pinned = append(r.Pinned, FileRef{App: appName, File: fileName})
+	return true
+}
+
+// RecordEdit moves the file to the front of the recent files and saves them.
+func RecordEdit(appName, fileName string) error {
+	recent, err := LoadRecentFiles()
+	if err != nil {
+		return err
+	}
+	for i, edit := range recent.Recent {
+		if edit.App == appName && edit.File == fileName {
+			recent.Recent = append(recent.Recent[:i], recent.Recent[i+1:]...)
+			break
+		}
+	}
+	edit := RecentEdit{FileRef: FileRef{App: appName, File: fileName}, Time: time.Now()}
+	recent.Recent = append([]RecentEdit{edit}, recent.Recent...)
+	if len(recent.Recent) > MaxRecentFiles {
+		recent.Recent = recent.Recent[:MaxRecentFiles]
+	}
+	return recent.Save()
+}
+
+func indexOfRef(refs []FileRef, appName, fileName string) int {
+	for i, ref := range refs {
+		if ref.App == appName && ref.File == fileName {
+			return i
+		}
+	}
+	return -1
+}
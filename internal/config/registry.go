@@ -5,30 +5,72 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
+// Policies applied by EditConfigFile when a post-hook exits non-zero.
+const (
+	OnFailureKeep    = "keep"
+	OnFailurePrompt  = "prompt"
+	OnFailureRestore = "restore"
+)
+
 type ConfigFile struct {
-	Description string   `toml:"description"`
-	Path        string   `toml:"path"`
-	PreHook     []string `toml:"pre_hook"`
-	PostHook    []string `toml:"post_hook"`
+	Description string `toml:"description"`
+	Path        string `toml:"path"`
+	PreHook     Hook   `toml:"pre_hook"`
+	PostHook    Hook   `toml:"post_hook"`
+	OnFailure   string `toml:"on_failure"`
+	Format      string `toml:"format"`
+	// Language names the syntax used to highlight the file in the TUI, e.g.
+	// "hyprlang" or "rasi". It defaults to Format, then to a guess from the
+	// file name.
+	Language string `toml:"language"`
+	// Editor overrides the editor command for this file, e.g. "code --wait".
+	Editor string `toml:"editor"`
+	// AlwaysRunPostHook runs the post-hook even when the edit left the file
+	// unchanged. It is a pointer so an override can set it back to false.
+	AlwaysRunPostHook *bool `toml:"always_run_post_hook"`
+	// Template seeds the file when it does not exist yet with its inline
+	// contents. TemplateFile does the same with a file, resolved against the
+	// registry file declaring it when relative.
+	Template     string `toml:"template"`
+	TemplateFile string `toml:"template_file"`
+	TemplateDir  string `toml:"-"`
+	// Default is the upstream copy of the file, such as the one HyDE ships
+	// under /usr/share/hyde, that the TUI can diff against. Relative paths
+	// are resolved against the registry file declaring it.
+	Default    string `toml:"default"`
+	DefaultDir string `toml:"-"`
+	// InheritHooks set to false opts the file out of the app's default hooks.
+	InheritHooks *bool `toml:"inherit_hooks"`
+	Remove       bool  `toml:"remove"`
 }
 
 type AppConfig struct {
-	Description string                `toml:"description"`
-	Icon        string                `toml:"icon"`
-	Files       map[string]ConfigFile `toml:"files"`
+	Description string `toml:"description"`
+	Icon        string `toml:"icon"`
+	// Editor is used for the app's files that don't set their own.
+	Editor string `toml:"editor"`
+	// PreHook and PostHook are inherited by files that define none.
+	PreHook  Hook                  `toml:"pre_hook"`
+	PostHook Hook                  `toml:"post_hook"`
+	Files    map[string]ConfigFile `toml:"files"`
+	Remove   bool                  `toml:"remove"`
 }
 
 type OrderedConfigRegistry struct {
 	AppsOrder []string
 	Apps      map[string]AppConfig
+	// Sources lists the registry files that were merged, lowest priority first.
+	Sources []string
 }
 
-func LoadConfigRegistry() (*OrderedConfigRegistry, error) {
+// RegistryPaths returns the config-registry.toml search paths, highest priority first.
+func RegistryPaths() []string {
 	configHome := os.Getenv("XDG_CONFIG_HOME")
 	if configHome == "" {
 		configHome = filepath.Join(os.Getenv("HOME"), ".config")
@@ -38,54 +80,215 @@ func LoadConfigRegistry() (*OrderedConfigRegistry, error) {
 		dataHome = filepath.Join(os.Getenv("HOME"), ".local", "share")
 	}
 
-	configPaths := []string{
+	return []string{
 		filepath.Join(configHome, "hyde", "config-registry.toml"),
 		filepath.Join(dataHome, "hyde", "config-registry.toml"),
 		"/usr/local/share/hyde/config-registry.toml",
 		"/usr/share/hyde/config-registry.toml",
 	}
+}
+
+// LoadConfigRegistry loads every registry layer found in RegistryPaths and
+// merges them, so user layers override or extend the system ones. Each layer
+// may be extended by drop-ins in a config-registry.d directory. An app or
+// file with `remove = true` drops the entry inherited from earlier files.
+func LoadConfigRegistry() (*OrderedConfigRegistry, error) {
+	configPaths := RegistryPaths()
+
+	registry := &OrderedConfigRegistry{
+		Apps: make(map[string]AppConfig),
+	}
 
-	var configPath string
-	for _, path := range configPaths {
-		if _, err := os.Stat(path); err == nil {
-			configPath = path
-			break
+	for i := len(configPaths) - 1; i >= 0; i-- {
+		for _, path := range registryLayerFiles(configPaths[i]) {
+			layer, err := loadRegistryFile(path)
+			if err != nil {
+				return nil, err
+			}
+			registry.merge(layer)
+			registry.Sources = append(registry.Sources, path)
 		}
 	}
 
-	if configPath == "" {
+	if len(registry.Sources) == 0 {
 		return nil, fmt.Errorf("config-registry.toml not found in any of the expected locations: %v", configPaths)
 	}
 
-	var (
-		appsOrder []string
-		apps      = make(map[string]AppConfig)
-	)
+	return registry, nil
+}
+
+// registryLayerFiles returns the files making up one registry layer: the
+// config-registry.toml itself followed by the *.toml drop-ins of the
+// config-registry.d directory next to it, in lexical order.
+func registryLayerFiles(registryPath string) []string {
+	var files []string
+	if _, err := os.Stat(registryPath); err == nil {
+		files = append(files, registryPath)
+	}
+
+	dropIns, _ := filepath.Glob(filepath.Join(filepath.Dir(registryPath), "config-registry.d", "*.toml"))
+	sort.Strings(dropIns)
+	for _, dropIn := range dropIns {
+		if info, err := os.Stat(dropIn); err == nil && !info.IsDir() {
+			files = append(files, dropIn)
+		}
+	}
 
-	var meta toml.MetaData
+	return files
+}
+
+func loadRegistryFile(configPath string) (*OrderedConfigRegistry, error) {
+	apps := make(map[string]AppConfig)
 	meta, err := toml.DecodeFile(configPath, &apps)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing config registry: %w", err)
+		return nil, fmt.Errorf("error parsing config registry %s: %w", configPath, err)
 	}
 
+	return newOrderedRegistry(apps, meta, configPath), nil
+}
+
+// newOrderedRegistry normalizes apps decoded from configPath and orders them
+// as they appear in the file.
+func newOrderedRegistry(apps map[string]AppConfig, meta toml.MetaData, configPath string) *OrderedConfigRegistry {
+	var appsOrder []string
+
 	// Normalize all keys to lower case
 	normApps := make(map[string]AppConfig)
 	for k, v := range apps {
+		for name, file := range v.Files {
+			if file.TemplateFile != "" {
+				file.TemplateDir = filepath.Dir(configPath)
+			}
+			if file.Default != "" {
+				file.DefaultDir = filepath.Dir(configPath)
+			}
+			v.Files[name] = file
+		}
 		normApps[strings.ToLower(k)] = v
 	}
+	// Apps may only appear through nested tables such as [kitty.files.main]
+	// in override layers, so take the first key component of every key.
+	seen := make(map[string]bool)
 	for _, key := range meta.Keys() {
-		if len(key) == 1 {
-			k := key[0]
-			if _, ok := apps[k]; ok {
-				appsOrder = append(appsOrder, strings.ToLower(k))
-			}
+		k := key[0]
+		name := strings.ToLower(k)
+		if _, ok := apps[k]; ok && !seen[name] {
+			seen[name] = true
+			appsOrder = append(appsOrder, name)
 		}
 	}
 
 	return &OrderedConfigRegistry{
 		AppsOrder: appsOrder,
 		Apps:      normApps,
-	}, nil
+	}
+}
+
+// merge applies a higher priority layer on top of the registry.
+func (r *OrderedConfigRegistry) merge(layer *OrderedConfigRegistry) {
+	for _, appName := range layer.AppsOrder {
+		app := layer.Apps[appName]
+
+		if app.Remove {
+			if _, ok := r.Apps[appName]; ok {
+				delete(r.Apps, appName)
+				r.AppsOrder = removeString(r.AppsOrder, appName)
+			}
+			continue
+		}
+
+		base, exists := r.Apps[appName]
+		if !exists {
+			r.AppsOrder = append(r.AppsOrder, appName)
+		}
+		r.Apps[appName] = mergeApp(base, app)
+	}
+}
+
+func mergeApp(base, over AppConfig) AppConfig {
+	if over.Description != "" {
+		base.Description = over.Description
+	}
+	if over.Icon != "" {
+		base.Icon = over.Icon
+	}
+	if over.Editor != "" {
+		base.Editor = over.Editor
+	}
+	if !over.PreHook.IsZero() {
+		base.PreHook = over.PreHook
+	}
+	if !over.PostHook.IsZero() {
+		base.PostHook = over.PostHook
+	}
+
+	files := make(map[string]ConfigFile, len(base.Files)+len(over.Files))
+	for name, file := range base.Files {
+		files[name] = file
+	}
+	for name, file := range over.Files {
+		if file.Remove {
+			delete(files, name)
+			continue
+		}
+		files[name] = mergeFile(files[name], file)
+	}
+	base.Files = files
+
+	return base
+}
+
+func mergeFile(base, over ConfigFile) ConfigFile {
+	if over.Description != "" {
+		base.Description = over.Description
+	}
+	if over.Path != "" {
+		base.Path = over.Path
+	}
+	if !over.PreHook.IsZero() {
+		base.PreHook = over.PreHook
+	}
+	if !over.PostHook.IsZero() {
+		base.PostHook = over.PostHook
+	}
+	if over.OnFailure != "" {
+		base.OnFailure = over.OnFailure
+	}
+	if over.Format != "" {
+		base.Format = over.Format
+	}
+	if over.Language != "" {
+		base.Language = over.Language
+	}
+	if over.Editor != "" {
+		base.Editor = over.Editor
+	}
+	if over.AlwaysRunPostHook != nil {
+		base.AlwaysRunPostHook = over.AlwaysRunPostHook
+	}
+	if over.Template != "" || over.TemplateFile != "" {
+		base.Template = over.Template
+		base.TemplateFile = over.TemplateFile
+		base.TemplateDir = over.TemplateDir
+	}
+	if over.Default != "" {
+		base.Default = over.Default
+		base.DefaultDir = over.DefaultDir
+	}
+	if over.InheritHooks != nil {
+		base.InheritHooks = over.InheritHooks
+	}
+	return base
+}
+
+func removeString(list []string, value string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	return out
 }
 
 func ExpandPath(path string) string {
@@ -124,6 +327,145 @@ func ExpandPath(path string) string {
 	return expanded
 }
 
+// FileNames returns the names of the app's files in sorted order.
+func (a AppConfig) FileNames() []string {
+	names := make([]string, 0, len(a.Files))
+	for name := range a.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveFile returns the named file with the app's default hooks filled in
+// where the file defines none and has not opted out, and the app's editor
+// where the file sets none.
+func (a AppConfig) ResolveFile(fileName string) ConfigFile {
+	file := a.Files[fileName]
+	if file.Editor == "" {
+		file.Editor = a.Editor
+	}
+	if a.InheritsPreHook(fileName) {
+		file.PreHook = a.PreHook
+	}
+	if a.InheritsPostHook(fileName) {
+		file.PostHook = a.PostHook
+	}
+	return file
+}
+
+// InheritsPreHook reports whether the file's pre-hook comes from the app.
+func (a AppConfig) InheritsPreHook(fileName string) bool {
+	file, ok := a.Files[fileName]
+	return ok && file.inheritsHooks() && file.PreHook.IsZero() && !a.PreHook.IsZero()
+}
+
+// InheritsPostHook reports whether the file's post-hook comes from the app.
+func (a AppConfig) InheritsPostHook(fileName string) bool {
+	file, ok := a.Files[fileName]
+	return ok && file.inheritsHooks() && file.PostHook.IsZero() && !a.PostHook.IsZero()
+}
+
+func (c *ConfigFile) inheritsHooks() bool {
+	return c.InheritHooks == nil || *c.InheritHooks
+}
+
+func (c *ConfigFile) alwaysRunsPostHook() bool {
+	return c.AlwaysRunPostHook != nil && *c.AlwaysRunPostHook
+}
+
+// UnresolvedVars returns the variables referenced by path that ExpandPath
+// would replace with an empty string.
+func UnresolvedVars(path string) []string {
+	var missing []string
+
+	envVarPattern := regexp.MustCompile(`\$\{([^}]+)\}`)
+	for _, match := range envVarPattern.FindAllStringSubmatch(path, -1) {
+		varExpr := match[1]
+		if strings.Contains(varExpr, ":-") {
+			parts := strings.SplitN(varExpr, ":-", 2)
+			if os.Getenv(parts[0]) == "" {
+				missing = append(missing, UnresolvedVars(parts[1])...)
+			}
+			continue
+		}
+		if os.Getenv(varExpr) == "" {
+			missing = append(missing, varExpr)
+		}
+	}
+
+	simpleVarPattern := regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+	for _, match := range simpleVarPattern.FindAllStringSubmatch(envVarPattern.ReplaceAllString(path, ""), -1) {
+		if os.Getenv(match[1]) == "" {
+			missing = append(missing, match[1])
+		}
+	}
+
+	return missing
+}
+
+// HighlightLanguage returns the language the file is highlighted as: its
+// Language, else its Format. Empty means guess from the file.
+func (c *ConfigFile) HighlightLanguage() string {
+	if c.Language != "" {
+		return c.Language
+	}
+	return c.Format
+}
+
+// HasTemplate reports whether a missing file can be created from a template.
+func (c *ConfigFile) HasTemplate() bool {
+	return c.Template != "" || c.TemplateFile != ""
+}
+
+// TemplatePath returns the absolute path of the file's TemplateFile, or ""
+// when it has none.
+func (c *ConfigFile) TemplatePath() string {
+	if c.TemplateFile == "" {
+		return ""
+	}
+	path := ExpandPath(c.TemplateFile)
+	if !filepath.IsAbs(path) && c.TemplateDir != "" {
+		path = filepath.Join(c.TemplateDir, path)
+	}
+	return path
+}
+
+// TemplateContent returns the contents used to seed the file, read from its
+// TemplateFile or else taken from its inline Template.
+func (c *ConfigFile) TemplateContent() (string, error) {
+	if path := c.TemplatePath(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		return string(data), nil
+	}
+	return c.Template, nil
+}
+
+// CreateFromTemplate writes the template to the file's path unless the file
+// already exists.
+func (c *ConfigFile) CreateFromTemplate() error {
+	if c.FileExists() {
+		return nil
+	}
+
+	content, err := c.TemplateContent()
+	if err != nil {
+		return err
+	}
+
+	path := ExpandPath(c.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return nil
+}
+
 func (c *ConfigFile) FileExists() bool {
 	expandedPath := ExpandPath(c.Path)
 	_, err := os.Stat(expandedPath)
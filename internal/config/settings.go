@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Settings are hydectl's own preferences, read from SettingsPath.
+type Settings struct {
+	// Editor is the command used to edit config files, e.g. "code --wait".
+	Editor string `toml:"editor"`
+	// EditorLineArgs is the argument template used to open editors hydectl
+	// doesn't know at a line, e.g. "--line {line} {file}".
+	EditorLineArgs string `toml:"editor_line_args"`
+	// Keys overrides TUI key bindings by action name, e.g.
+	// down = ["down", "n"].
+	Keys map[string][]string `toml:"keys"`
+	// HighlightStyle is the chroma style of the TUI preview, e.g. "dracula".
+	HighlightStyle string `toml:"highlight_style"`
+	// Monochrome draws the TUI without colors, like NO_COLOR does.
+	Monochrome bool `toml:"monochrome"`
+	// BackupRetention is the number of snapshots kept per config file. Zero
+	// means DefaultBackupRetention.
+	BackupRetention int `toml:"backup_retention"`
+}
+
+// SettingsPath returns the location of hydectl's config file.
+func SettingsPath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return filepath.Join(configHome, "hydectl", "config.toml")
+}
+
+// LoadSettings reads hydectl's config file. A missing file yields the zero
+// Settings.
+func LoadSettings() (Settings, error) {
+	var settings Settings
+	path := SettingsPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return settings, nil
+	}
+	if _, err := toml.DecodeFile(path, &settings); err != nil {
+		return Settings{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// SaveSetting sets a top-level string key in hydectl's config file, creating
+// the file if needed. The rest of the file, comments included, is kept.
+func SaveSetting(key, value string) error {
+	path := SettingsPath()
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	entry := fmt.Sprintf("%s = %s", key, strconv.Quote(value))
+	keyPattern := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(key) + `\s*=`)
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	// Top-level keys must come before the first table.
+	insertAt := len(lines)
+	replaced := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			insertAt = i
+			break
+		}
+		if keyPattern.MatchString(line) {
+			lines[i] = entry
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		for insertAt > 0 && insertAt < len(lines) && strings.TrimSpace(lines[insertAt-1]) == "" {
+			insertAt--
+		}
+		lines = append(lines[:insertAt], append([]string{entry}, lines[insertAt:]...)...)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
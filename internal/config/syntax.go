@@ -0,0 +1,318 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// File formats that can be checked with CheckSyntax.
+const (
+	FormatTOML     = "toml"
+	FormatJSON     = "json"
+	FormatJSONC    = "jsonc"
+	FormatINI      = "ini"
+	FormatYAML     = "yaml"
+	FormatCSS      = "css"
+	FormatHyprlang = "hyprlang"
+)
+
+// SyntaxFormats lists the formats accepted in the registry `format` field.
+var SyntaxFormats = []string{FormatTOML, FormatJSON, FormatJSONC, FormatINI, FormatYAML, FormatCSS, FormatHyprlang}
+
+type SyntaxError struct {
+	Format  string
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", e.Format, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Format, e.Message)
+}
+
+// IsSyntaxFormat reports whether format is supported by CheckSyntax.
+func IsSyntaxFormat(format string) bool {
+	for _, f := range SyntaxFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSyntax parses the file at path as format. It returns nil when the
+// file is valid, the format is empty or the file does not exist.
+func CheckSyntax(format, path string) *SyntaxError {
+	if format == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return &SyntaxError{Format: format, Message: err.Error()}
+	}
+
+	var line int
+	switch format {
+	case FormatTOML:
+		var v map[string]interface{}
+		if _, err = toml.Decode(string(data), &v); err != nil {
+			var parseErr toml.ParseError
+			if errors.As(err, &parseErr) {
+				line, err = parseErr.Position.Line, errors.New(parseErr.Message)
+			}
+		}
+	case FormatJSON:
+		line, err = checkJSON(data)
+	case FormatJSONC:
+		line, err = checkJSON(stripJSONC(data))
+	case FormatYAML:
+		var v interface{}
+		if err = yaml.Unmarshal(data, &v); err != nil {
+			line, err = yamlErrorLine(err)
+		}
+	case FormatINI:
+		line, err = checkINI(data)
+	case FormatCSS:
+		line, err = checkCSS(data)
+	case FormatHyprlang:
+		line, err = checkHyprlang(data)
+	default:
+		return &SyntaxError{Format: format, Message: "unknown format"}
+	}
+
+	if err != nil {
+		return &SyntaxError{Format: format, Line: line, Message: err.Error()}
+	}
+	return nil
+}
+
+func checkJSON(data []byte) (int, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if err == nil {
+		return 0, nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return lineAtOffset(data, syntaxErr.Offset), err
+	}
+	return 0, err
+}
+
+func lineAtOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// stripJSONC blanks out comments and trailing commas so the result can be
+// parsed as JSON. Byte offsets and line numbers are preserved.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	inString = false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ',':
+			j := i + 1
+			for j < len(out) && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j++
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+
+	return out
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+func yamlErrorLine(err error) (int, error) {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		msg = strings.TrimPrefix(msg, m[0]+": ")
+		return line, errors.New(msg)
+	}
+	return 0, errors.New(msg)
+}
+
+func checkINI(data []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return lineNum, errors.New("unterminated section header")
+			}
+		case !strings.Contains(line, "="):
+			return lineNum, errors.New("expected 'key = value'")
+		case strings.TrimSpace(line[:strings.Index(line, "=")]) == "":
+			return lineNum, errors.New("missing key before '='")
+		}
+	}
+	return 0, scanner.Err()
+}
+
+func checkCSS(data []byte) (int, error) {
+	var open []int
+	line := 1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '\n':
+			line++
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			start := line
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return start, errors.New("unterminated comment")
+			}
+			line += bytes.Count(data[i:i+2+end], []byte("\n"))
+			i += end + 3
+		case c == '"' || c == '\'':
+			start := line
+			j := i + 1
+			for ; j < len(data) && data[j] != c; j++ {
+				if data[j] == '\\' {
+					j++
+				} else if data[j] == '\n' {
+					return start, errors.New("unterminated string")
+				}
+			}
+			if j >= len(data) {
+				return start, errors.New("unterminated string")
+			}
+			i = j
+		case c == '{':
+			open = append(open, line)
+		case c == '}':
+			if len(open) == 0 {
+				return line, errors.New("unexpected '}'")
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return open[len(open)-1], errors.New("unclosed '{'")
+	}
+	return 0, nil
+}
+
+func checkHyprlang(data []byte) (int, error) {
+	var open []int
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(stripHyprlangComment(scanner.Text()))
+		switch {
+		case line == "":
+		case line == "}":
+			if len(open) == 0 {
+				return lineNum, errors.New("unexpected '}'")
+			}
+			open = open[:len(open)-1]
+		case strings.HasSuffix(line, "{"):
+			if strings.TrimSpace(strings.TrimSuffix(line, "{")) == "" {
+				return lineNum, errors.New("missing category name before '{'")
+			}
+			open = append(open, lineNum)
+		case strings.Contains(line, "="):
+			key := strings.TrimSpace(line[:strings.Index(line, "=")])
+			if key == "" || key == "$" {
+				return lineNum, errors.New("missing key before '='")
+			}
+		default:
+			return lineNum, errors.New("expected 'key = value'")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if len(open) > 0 {
+		return open[len(open)-1], errors.New("unclosed '{'")
+	}
+	return 0, nil
+}
+
+// stripHyprlangComment cuts a line at the first '#', keeping "##" which
+// hyprlang uses to escape a literal '#'.
+func stripHyprlangComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] != '#' {
+			continue
+		}
+		if i+1 < len(line) && line[i+1] == '#' {
+			i++
+			continue
+		}
+		return line[:i]
+	}
+	return line
+}
@@ -0,0 +1,299 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"hydectl/internal/highlight"
+
+	"github.com/BurntSushi/toml"
+)
+
+// ValidationIssue is a single problem found in a registry file.
+type ValidationIssue struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+type keyLocation struct {
+	file string
+	line int
+}
+
+// ValidateRegistry loads every registry layer like LoadConfigRegistry and
+// reports all problems found, both in the individual files and in the
+// merged result.
+func ValidateRegistry() ([]ValidationIssue, error) {
+	var (
+		issues    []ValidationIssue
+		sources   []string
+		locations = make(map[string]keyLocation)
+		registry  = &OrderedConfigRegistry{Apps: make(map[string]AppConfig)}
+	)
+
+	// appNames maps lowercased app names to their spelling where first seen.
+	appNames := make(map[string]string)
+	configPaths := RegistryPaths()
+	for i := len(configPaths) - 1; i >= 0; i-- {
+		for _, path := range registryLayerFiles(configPaths[i]) {
+			sources = append(sources, path)
+
+			lines, err := tomlKeyLines(path)
+			if err != nil {
+				issues = append(issues, ValidationIssue{File: path, Message: err.Error()})
+				continue
+			}
+
+			apps := make(map[string]AppConfig)
+			meta, err := toml.DecodeFile(path, &apps)
+			if err != nil {
+				issue := ValidationIssue{File: path, Message: err.Error()}
+				var parseErr toml.ParseError
+				if errors.As(err, &parseErr) {
+					issue.Line = parseErr.Position.Line
+					issue.Message = parseErr.Message
+				}
+				issues = append(issues, issue)
+				continue
+			}
+
+			for _, key := range meta.Undecoded() {
+				issues = append(issues, ValidationIssue{
+					File:    path,
+					Line:    lines[strings.Join(key, ".")],
+					Message: fmt.Sprintf("unknown key %q", key.String()),
+				})
+			}
+
+			checked := make(map[string]bool)
+			for _, key := range meta.Keys() {
+				// Compare each app name once per file, at its first key,
+				// against the names of every file read so far.
+				if name := key[0]; !checked[name] {
+					checked[name] = true
+					norm := strings.ToLower(name)
+					if prev, ok := appNames[norm]; ok && prev != name {
+						issues = append(issues, ValidationIssue{
+							File:    path,
+							Line:    lines[strings.Join(key, ".")],
+							Message: fmt.Sprintf("app %q duplicates %q after lowercase normalization", name, prev),
+						})
+					} else if !ok {
+						appNames[norm] = name
+					}
+				}
+
+				locations[strings.ToLower(strings.Join(key, "."))] = keyLocation{file: path, line: lines[strings.Join(key, ".")]}
+			}
+
+			registry.merge(newOrderedRegistry(apps, meta, path))
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("config-registry.toml not found in any of the expected locations: %v", configPaths)
+	}
+
+	locate := func(keys ...string) keyLocation {
+		for _, key := range keys {
+			if loc, ok := locations[key]; ok {
+				return loc
+			}
+		}
+		return keyLocation{file: sources[len(sources)-1]}
+	}
+
+	for _, appName := range registry.AppsOrder {
+		app := registry.Apps[appName]
+		appHooks := []struct {
+			key  string
+			hook Hook
+		}{
+			{"pre_hook", app.PreHook},
+			{"post_hook", app.PostHook},
+		}
+		for _, h := range appHooks {
+			if h.hook.Executable() == "" {
+				continue
+			}
+			if _, err := exec.LookPath(h.hook.Executable()); err != nil {
+				loc := locate(appName+"."+h.key, appName)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s %s executable %q not found in PATH", appName, h.key, h.hook.Executable())})
+			}
+		}
+
+		if args := splitCommand(app.Editor); len(args) > 0 {
+			if _, err := exec.LookPath(args[0]); err != nil {
+				loc := locate(appName+".editor", appName)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s editor %q not found in PATH", appName, args[0])})
+			}
+		}
+
+		if len(app.Files) == 0 {
+			loc := locate(appName)
+			issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("app %q has no files", appName)})
+			continue
+		}
+
+		for _, fileName := range app.FileNames() {
+			file := app.Files[fileName]
+			fileKey := appName + ".files." + strings.ToLower(fileName)
+
+			if strings.TrimSpace(file.Path) == "" {
+				loc := locate(fileKey)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s has an empty path", appName, fileName)})
+			} else {
+				for _, name := range UnresolvedVars(file.Path) {
+					loc := locate(fileKey+".path", fileKey)
+					issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s path references unset variable $%s", appName, fileName, name)})
+				}
+			}
+
+			switch file.OnFailure {
+			case "", OnFailureKeep, OnFailurePrompt, OnFailureRestore:
+			default:
+				loc := locate(fileKey+".on_failure", fileKey)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s on_failure must be one of %q, %q or %q, got %q", appName, fileName, OnFailureKeep, OnFailurePrompt, OnFailureRestore, file.OnFailure)})
+			}
+
+			if file.Format != "" && !IsSyntaxFormat(file.Format) {
+				loc := locate(fileKey+".format", fileKey)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s has unknown format %q, expected one of: %s", appName, fileName, file.Format, strings.Join(SyntaxFormats, ", "))})
+			}
+			if file.Language != "" && highlight.Get(file.Language) == nil {
+				loc := locate(fileKey+".language", fileKey)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s has unknown language %q", appName, fileName, file.Language)})
+			}
+
+			if file.Template != "" && file.TemplateFile != "" {
+				loc := locate(fileKey+".template_file", fileKey)
+				issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s sets both template and template_file", appName, fileName)})
+			}
+			if path := file.TemplatePath(); path != "" {
+				if _, err := os.Stat(path); err != nil {
+					loc := locate(fileKey+".template_file", fileKey)
+					issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s template_file %s does not exist", appName, fileName, path)})
+				}
+			}
+
+			if path := file.DefaultPath(); path != "" {
+				if _, err := os.Stat(path); err != nil {
+					loc := locate(fileKey+".default", fileKey)
+					issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s default %s does not exist", appName, fileName, path)})
+				}
+			}
+
+			if args := splitCommand(file.Editor); len(args) > 0 {
+				if _, err := exec.LookPath(args[0]); err != nil {
+					loc := locate(fileKey+".editor", fileKey)
+					issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s editor %q not found in PATH", appName, fileName, args[0])})
+				}
+			}
+
+			hooks := []struct {
+				key  string
+				hook Hook
+			}{
+				{"pre_hook", file.PreHook},
+				{"post_hook", file.PostHook},
+			}
+			for _, h := range hooks {
+				if h.hook.Executable() == "" {
+					continue
+				}
+				if _, err := exec.LookPath(h.hook.Executable()); err != nil {
+					loc := locate(fileKey+"."+h.key, fileKey)
+					issues = append(issues, ValidationIssue{File: loc.file, Line: loc.line, Message: fmt.Sprintf("%s/%s %s executable %q not found in PATH", appName, fileName, h.key, h.hook.Executable())})
+				}
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// tomlKeyLines maps dotted TOML keys to the line that defines them. It is a
+// line based scan, good enough to point validation messages at the right spot.
+func tomlKeyLines(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make(map[string]int)
+	var table []string
+	lineNum := 0
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			header := strings.Trim(strings.SplitN(line, "]", 2)[0], "[ ")
+			if strings.HasPrefix(line, "[[") {
+				header = strings.Trim(strings.SplitN(line, "]]", 2)[0], "[ ")
+			}
+			table = splitTomlKey(header)
+			for i := range table {
+				key := strings.Join(table[:i+1], ".")
+				if _, ok := lines[key]; !ok {
+					lines[key] = lineNum
+				}
+			}
+			continue
+		}
+
+		if idx := strings.Index(line, "="); idx > 0 {
+			key := append(append([]string{}, table...), splitTomlKey(line[:idx])...)
+			joined := strings.Join(key, ".")
+			if _, ok := lines[joined]; !ok {
+				lines[joined] = lineNum
+			}
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+func splitTomlKey(key string) []string {
+	var (
+		parts   []string
+		current strings.Builder
+		quote   rune
+	)
+	for _, r := range key {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '.':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(parts, strings.TrimSpace(current.String()))
+}
@@ -0,0 +1,49 @@
+// Package highlight picks chroma lexers for config files and registers
+// lexers for the formats HyDE uses that chroma doesn't ship.
+package highlight
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// Get returns the lexer for a language name, such as a registry `language`
+// or `format` value. Names chroma only knows as an extension, like
+// "jsonc", are resolved through it. It returns nil for unknown names.
+func Get(language string) chroma.Lexer {
+	language = strings.ToLower(language)
+	if lexer := lexers.Get(language); lexer != nil {
+		return lexer
+	}
+	return lexers.Match("file." + language)
+}
+
+// Lexer picks the lexer for a config file: the declared language first, then
+// the file name, then the directory for Hyprland's *.conf files, and finally
+// the content. Other *.conf and *rc files fall back to INI. It returns nil
+// when nothing matches.
+func Lexer(language, path, content string) chroma.Lexer {
+	if language != "" {
+		if lexer := Get(language); lexer != nil {
+			return lexer
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	if lexer := lexers.Match(name); lexer != nil {
+		return lexer
+	}
+	if strings.HasSuffix(name, ".conf") && filepath.Base(filepath.Dir(path)) == "hypr" {
+		return Hyprlang
+	}
+	if lexer := lexers.Analyse(content); lexer != nil {
+		return lexer
+	}
+	if strings.HasSuffix(name, ".conf") || strings.HasSuffix(name, "rc") {
+		return lexers.Get("ini")
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package highlight
+
+import (
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+// Hyprlang highlights Hyprland's config language: `$var = value`
+// definitions, keyword lines such as `bind = $mainMod, Q, exec, kitty`, and
+// nested `category { ... }` blocks.
+var Hyprlang = lexers.Register(chroma.MustNewLexer(
+	&chroma.Config{
+		Name:      "Hyprlang",
+		Aliases:   []string{"hyprlang", "hyprland", "hypr"},
+		Filenames: []string{"hyprland.conf", "hyprlock.conf", "hypridle.conf", "hyprpaper.conf", "*.hl"},
+	},
+	hyprlangRules,
+))
+
+func hyprlangRules() chroma.Rules {
+	return chroma.Rules{
+		"root": {
+			{Pattern: `\s+`, Type: chroma.Text},
+			{Pattern: `#.*`, Type: chroma.CommentSingle},
+			{Pattern: `(\$[\w-]+)([ \t]*)(=)`, Type: chroma.ByGroups(chroma.NameVariable, chroma.Text, chroma.Operator), Mutator: chroma.Push("value")},
+			{Pattern: `(bind[a-z]*|unbind|exec(?:-once|-shutdown)?|execr(?:-once)?|source|env|windowrule(?:v2)?|layerrule|workspace|monitor|submap|plugin|permission|gesture)([ \t]*)(=)`, Type: chroma.ByGroups(chroma.Keyword, chroma.Text, chroma.Operator), Mutator: chroma.Push("value")},
+			{Pattern: `([\w.:-]+)([ \t]*)(=)`, Type: chroma.ByGroups(chroma.NameAttribute, chroma.Text, chroma.Operator), Mutator: chroma.Push("value")},
+			{Pattern: `([\w.:-]+)([ \t]*)(\{)`, Type: chroma.ByGroups(chroma.NameNamespace, chroma.Text, chroma.Punctuation)},
+			{Pattern: `\}`, Type: chroma.Punctuation},
+			{Pattern: `[^\s#]+`, Type: chroma.Text},
+		},
+		"value": {
+			{Pattern: `\n`, Type: chroma.Text, Mutator: chroma.Pop(1)},
+			{Pattern: `##`, Type: chroma.Text},
+			{Pattern: `#.*`, Type: chroma.CommentSingle},
+			{Pattern: `\$[\w-]+`, Type: chroma.NameVariable},
+			{Pattern: `(rgba?)(\()([0-9a-fA-F.,\s]*)(\))`, Type: chroma.ByGroups(chroma.NameFunction, chroma.Punctuation, chroma.LiteralNumberHex, chroma.Punctuation)},
+			{Pattern: `0x[0-9a-fA-F]+\b`, Type: chroma.LiteralNumberHex},
+			{Pattern: `\b(true|false|yes|no|on|off)\b`, Type: chroma.KeywordConstant},
+			{Pattern: `-?\d+(\.\d+)?\b`, Type: chroma.LiteralNumber},
+			{Pattern: `"[^"\n]*"`, Type: chroma.LiteralStringDouble},
+			{Pattern: `'[^'\n]*'`, Type: chroma.LiteralStringSingle},
+			{Pattern: `,`, Type: chroma.Punctuation},
+			{Pattern: `[^\S\n]+`, Type: chroma.Text},
+			{Pattern: `[^\s,#$"']+`, Type: chroma.Text},
+			{Pattern: `[$"']`, Type: chroma.Text},
+		},
+	}
+}
+
+// Dunstrc highlights dunst's INI dialect, where colors are quoted hex
+// strings such as "#1e1e2e".
+var Dunstrc = lexers.Register(chroma.MustNewLexer(
+	&chroma.Config{
+		Name:      "dunstrc",
+		Aliases:   []string{"dunstrc", "dunst"},
+		Filenames: []string{"dunstrc", "*.dunstrc"},
+	},
+	dunstrcRules,
+))
+
+func dunstrcRules() chroma.Rules {
+	return chroma.Rules{
+		"root": {
+			{Pattern: `\s+`, Type: chroma.Text},
+			{Pattern: `[#;].*`, Type: chroma.CommentSingle},
+			{Pattern: `(\[)([^\]\n]+)(\])`, Type: chroma.ByGroups(chroma.Punctuation, chroma.Keyword, chroma.Punctuation)},
+			{Pattern: `([\w-]+)([ \t]*)(=)`, Type: chroma.ByGroups(chroma.NameAttribute, chroma.Text, chroma.Operator), Mutator: chroma.Push("value")},
+			{Pattern: `\S+`, Type: chroma.Text},
+		},
+		"value": {
+			{Pattern: `\n`, Type: chroma.Text, Mutator: chroma.Pop(1)},
+			{Pattern: `"`, Type: chroma.LiteralStringDouble, Mutator: chroma.Push("string")},
+			{Pattern: `#[0-9a-fA-F]{3,8}\b`, Type: chroma.LiteralNumberHex},
+			{Pattern: `\b(true|false|yes|no)\b`, Type: chroma.KeywordConstant},
+			{Pattern: `-?\d+(\.\d+)?\b`, Type: chroma.LiteralNumber},
+			{Pattern: `[^\S\n]+`, Type: chroma.Text},
+			{Pattern: `[^\s"]+`, Type: chroma.Text},
+		},
+		"string": {
+			{Pattern: `"`, Type: chroma.LiteralStringDouble, Mutator: chroma.Pop(1)},
+			{Pattern: `\\.`, Type: chroma.LiteralStringEscape},
+			{Pattern: `#[0-9a-fA-F]{3,8}\b`, Type: chroma.LiteralNumberHex},
+			{Pattern: `\n`, Type: chroma.Error, Mutator: chroma.Pop(2)},
+			{Pattern: `[^"\\#\n]+`, Type: chroma.LiteralStringDouble},
+			{Pattern: `#`, Type: chroma.LiteralStringDouble},
+		},
+	}
+}
+
+// Rasi highlights rofi themes: CSS-like sections of `property: value;`
+// with `@variables`, `var()` and `@import`.
+var Rasi = lexers.Register(chroma.MustNewLexer(
+	&chroma.Config{
+		Name:      "Rasi",
+		Aliases:   []string{"rasi", "rofi"},
+		Filenames: []string{"*.rasi"},
+	},
+	rasiRules,
+))
+
+func rasiRules() chroma.Rules {
+	return chroma.Rules{
+		"comments": {
+			{Pattern: `/\*(.|\n)*?\*/`, Type: chroma.CommentMultiline},
+			{Pattern: `//.*`, Type: chroma.CommentSingle},
+		},
+		"root": {
+			{Pattern: `\s+`, Type: chroma.Text},
+			chroma.Include("comments"),
+			{Pattern: `@(import|theme|media)\b`, Type: chroma.KeywordNamespace},
+			{Pattern: `"[^"\n]*"`, Type: chroma.LiteralStringDouble},
+			{Pattern: `\{`, Type: chroma.Punctuation, Mutator: chroma.Push("block")},
+			{Pattern: `[,()]`, Type: chroma.Punctuation},
+			{Pattern: `[^\s{,()/"]+`, Type: chroma.NameTag},
+			{Pattern: `/`, Type: chroma.Text},
+		},
+		"block": {
+			{Pattern: `\s+`, Type: chroma.Text},
+			chroma.Include("comments"),
+			{Pattern: `\}`, Type: chroma.Punctuation, Mutator: chroma.Pop(1)},
+			{Pattern: `([\w-]+)(\s*)(:)`, Type: chroma.ByGroups(chroma.NameProperty, chroma.Text, chroma.Punctuation), Mutator: chroma.Push("value")},
+			{Pattern: `[^\s}/]+`, Type: chroma.Text},
+			{Pattern: `/`, Type: chroma.Text},
+		},
+		"value": {
+			{Pattern: `;`, Type: chroma.Punctuation, Mutator: chroma.Pop(1)},
+			{Pattern: `\}`, Type: chroma.Punctuation, Mutator: chroma.Pop(2)},
+			{Pattern: `\s+`, Type: chroma.Text},
+			chroma.Include("comments"),
+			{Pattern: `"[^"\n]*"`, Type: chroma.LiteralStringDouble},
+			{Pattern: `#[0-9a-fA-F]{3,8}\b`, Type: chroma.LiteralNumberHex},
+			{Pattern: `@[\w-]+`, Type: chroma.NameVariable},
+			{Pattern: `(var|env|rgba?|hsla?|calc)(\()`, Type: chroma.ByGroups(chroma.NameFunction, chroma.Punctuation)},
+			{Pattern: `[()\[\],]`, Type: chroma.Punctuation},
+			{Pattern: `-?\d+(\.\d+)?(px|em|ch|mm|%)?`, Type: chroma.LiteralNumber},
+			{Pattern: `\b(true|false|inherit|none)\b`, Type: chroma.KeywordConstant},
+			{Pattern: `[\w-]+`, Type: chroma.NameConstant},
+			{Pattern: `[^;}\s]`, Type: chroma.Text},
+		},
+	}
+}
@@ -1,12 +1,128 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
-
-var (
-	ColorBrightCyan   = lipgloss.Color("51")  // cyan
-	ColorBrightBlack  = lipgloss.Color("240") // gray
-	ColorBrightYellow = lipgloss.Color("226") // yellow
-	ColorBrightGreen  = lipgloss.Color("82")  // green
-	ColorBrightRed    = lipgloss.Color("196") // red
-	ColorDim          = lipgloss.Color("245") // dim gray
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
+
+// Theme is the palette the TUI is drawn with.
+type Theme struct {
+	Accent    lipgloss.TerminalColor // focused borders and column headers
+	Title     lipgloss.TerminalColor // window title and the expanded app
+	Highlight lipgloss.TerminalColor // selected file, search matches
+	Text      lipgloss.TerminalColor // file names
+	Muted     lipgloss.TerminalColor // inactive apps, missing files, footer
+	Subtle    lipgloss.TerminalColor // separators and line numbers
+	Border    lipgloss.TerminalColor // outer frame
+	Dim       lipgloss.TerminalColor // notes and status output
+	Success   lipgloss.TerminalColor
+	Error     lipgloss.TerminalColor
+	OnAccent  lipgloss.TerminalColor // text drawn on a Highlight background
+
+	// Monochrome disables colors, including syntax highlighting, and
+	// relies on bold, underline and reverse video instead.
+	Monochrome bool
+}
+
+// DefaultTheme returns the built-in ANSI 256 palette, used when no HyDE
+// theme colors are available.
+func DefaultTheme() Theme {
+	return Theme{
+		Accent:    lipgloss.Color("51"),
+		Title:     lipgloss.Color("86"),
+		Highlight: lipgloss.Color("226"),
+		Text:      lipgloss.Color("15"),
+		Muted:     lipgloss.Color("244"),
+		Subtle:    lipgloss.Color("240"),
+		Border:    lipgloss.Color("238"),
+		Dim:       lipgloss.Color("245"),
+		Success:   lipgloss.Color("82"),
+		Error:     lipgloss.Color("196"),
+		OnAccent:  lipgloss.Color("0"),
+	}
+}
+
+// MonochromeTheme returns a theme without any colors.
+func MonochromeTheme() Theme {
+	none := lipgloss.NoColor{}
+	return Theme{
+		Accent: none, Title: none, Highlight: none, Text: none, Muted: none,
+		Subtle: none, Border: none, Dim: none, Success: none, Error: none,
+		OnAccent:   none,
+		Monochrome: true,
+	}
+}
+
+// LoadTheme picks the TUI palette. NO_COLOR or monochrome select
+// MonochromeTheme; otherwise the colors wallbash generated for the active
+// HyDE theme are used, falling back to DefaultTheme.
+func LoadTheme(monochrome bool) Theme {
+	if monochrome || os.Getenv("NO_COLOR") != "" {
+		// NO_COLOR makes lipgloss drop bold and underline too, which are
+		// all that tells the selection apart without colors.
+		if lipgloss.ColorProfile() == termenv.Ascii && os.Getenv("TERM") != "dumb" {
+			lipgloss.SetColorProfile(termenv.ANSI)
+		}
+		return MonochromeTheme()
+	}
+	theme := DefaultTheme()
+	colors, err := readWallbashColors(WallbashColorsPath())
+	if err != nil {
+		return theme
+	}
+
+	// wallbash derives four primary colors from the wallpaper, each with
+	// nine accents ordered from dark to light (light to dark in light mode).
+	set := func(dst *lipgloss.TerminalColor, name string) {
+		if hex, ok := colors[name]; ok {
+			*dst = lipgloss.Color("#" + hex)
+		}
+	}
+	set(&theme.Accent, "dcol_1xa7")
+	set(&theme.Title, "dcol_2xa7")
+	set(&theme.Highlight, "dcol_3xa8")
+	set(&theme.Text, "dcol_txt1")
+	set(&theme.Muted, "dcol_1xa5")
+	set(&theme.Subtle, "dcol_1xa4")
+	set(&theme.Border, "dcol_1xa3")
+	set(&theme.Dim, "dcol_1xa6")
+	set(&theme.OnAccent, "dcol_pry1")
+	return theme
+}
+
+// WallbashColorsPath returns the file wallbash writes the active theme's
+// colors to.
+func WallbashColorsPath() string {
+	cacheHome := os.Getenv("XDG_CACHE_HOME")
+	if cacheHome == "" {
+		cacheHome = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(cacheHome, "hyde", "wall.dcol")
+}
+
+var dcolPattern = regexp.MustCompile(`^(dcol_\w+)="?#?([0-9A-Fa-f]{6})"?$`)
+
+// readWallbashColors parses the dcol_name="RRGGBB" lines of a wallbash
+// color file, skipping the rgba variants and anything else.
+func readWallbashColors(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	colors := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if match := dcolPattern.FindStringSubmatch(strings.TrimSpace(scanner.Text())); match != nil {
+			colors[match[1]] = match[2]
+		}
+	}
+	return colors, scanner.Err()
+}
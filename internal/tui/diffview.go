@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"hydectl/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// errNoDiffBase reports that none of the diff bases tried is available.
+var errNoDiffBase = errors.New("no diff base available")
+
+// cycleDiffBase switches the preview to the next diff base available for
+// the selected file, and back to the plain contents after the last one.
+func (m *Model) cycleDiffBase() {
+	if len(m.fileList) == 0 || m.activeFileTab >= len(m.fileList) {
+		return
+	}
+	fileName := m.fileList[m.activeFileTab]
+	fileConfig, _ := m.lookupFile(fileName)
+
+	start := 0
+	for i, base := range config.DiffBases {
+		if base == m.diffBase {
+			start = i + 1
+		}
+	}
+	if start == len(config.DiffBases) {
+		m.diffBase = ""
+		m.updatePreview(fileName)
+		return
+	}
+
+	// Which of the remaining bases exist is only known once they are read,
+	// so the first one stands in until the load reports back.
+	wasRaw := m.diffBase == ""
+	m.diffBase = config.DiffBases[start]
+	m.startDiffLoad(fileName, fileConfig, config.DiffBases[start:], wasRaw)
+}
+
+// showDiff fills the preview with a diff of the file against the selected
+// diff base, from the cache or else loaded in the background.
+func (m *Model) showDiff(fileName string, fileConfig config.ConfigFile) {
+	app, file := m.fileTarget(fileName)
+	key := diffKeyFor(app, file, fileConfig, m.diffBase)
+	if content, ok := m.previewCache.get(key); ok {
+		m.cancelPreviewLoad()
+		m.setDiffContent(key, content)
+		return
+	}
+	if m.previewLoading != nil && m.previewLoading.key == key {
+		return
+	}
+	m.startDiffLoad(fileName, fileConfig, []string{m.diffBase}, false)
+}
+
+// diffKeyFor returns the cache key of a file's diff against base.
+func diffKeyFor(app, file string, fileConfig config.ConfigFile, base string) previewKey {
+	key := previewKey{
+		path:        config.ExpandPath(fileConfig.Path),
+		diffBase:    base,
+		baseVersion: config.DiffBaseVersion(app, file, fileConfig, base),
+	}
+	if info, err := os.Stat(key.path); err == nil {
+		key.modTime, key.size = info.ModTime(), info.Size()
+	}
+	return key
+}
+
+// startDiffLoad queues a diff of the file against the first of bases that is
+// available. warn shows a warning when none is.
+func (m *Model) startDiffLoad(fileName string, fileConfig config.ConfigFile, bases []string, warn bool) {
+	app, file := m.fileTarget(fileName)
+	key := diffKeyFor(app, file, fileConfig, bases[0])
+
+	m.cancelPreviewLoad()
+	m.previewSeq++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.previewLoading = &previewLoad{seq: m.previewSeq, key: key, cancel: cancel, warnNoDiff: warn}
+	m.previewCmd = loadDiff(ctx, m.previewSeq, key, bases, app, file, fileConfig, m.theme)
+
+	// Keep showing a diff that is only being reloaded, to avoid flicker.
+	if m.previewShown.path != key.path || m.previewShown.diffBase != key.diffBase {
+		m.previewShown = previewKey{}
+		m.previewMatchIndices = nil
+		m.previewMatchIndex = 0
+		m.diffLabel = key.diffBase
+		m.diffLineMap = nil
+		m.previewViewport.SetContent(lipgloss.NewStyle().Foreground(m.theme.Dim).Render("⏳ Loading diff of " + fileName + "..."))
+	}
+}
+
+// loadDiff diffs a file in the background against the first of bases that
+// is available. With a single base, its error is shown in place of the diff;
+// with several, unavailable ones are skipped and errNoDiffBase is reported
+// when none is left.
+func loadDiff(ctx context.Context, seq int, key previewKey, bases []string, app, file string, fileConfig config.ConfigFile, theme Theme) tea.Cmd {
+	return func() tea.Msg {
+		for _, base := range bases {
+			if err := ctx.Err(); err != nil {
+				return previewLoadedMsg{seq: seq, key: key, err: err}
+			}
+			version := config.DiffBaseVersion(app, file, fileConfig, base)
+			baseContent, label, err := config.DiffBaseContent(app, file, fileConfig, base)
+			if err != nil {
+				if len(bases) == 1 {
+					dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+					return previewLoadedMsg{seq: seq, key: key, content: previewContent{lines: []string{dimStyle.Render(err.Error())}, label: base}}
+				}
+				continue
+			}
+			key.diffBase, key.baseVersion = base, version
+			current, _ := os.ReadFile(key.path)
+			return previewLoadedMsg{seq: seq, key: key, content: renderDiff(theme, label, key.path, baseContent, string(current))}
+		}
+		return previewLoadedMsg{seq: seq, key: key, err: errNoDiffBase}
+	}
+}
+
+// handleNoDiffBase returns to the plain contents when cycling found no diff
+// base for the selected file.
+func (m *Model) handleNoDiffBase(seq int) {
+	if m.previewLoading == nil || m.previewLoading.seq != seq {
+		return
+	}
+	warn := m.previewLoading.warnNoDiff
+	m.previewLoading.cancel()
+	m.previewLoading = nil
+	m.diffBase = ""
+	if len(m.fileList) == 0 || m.activeFileTab >= len(m.fileList) {
+		return
+	}
+	fileName := m.fileList[m.activeFileTab]
+	if warn {
+		m.editStatus = []string{"⚠️  No backup, default or git version of " + fileName + " to diff against"}
+	}
+	m.updatePreview(fileName)
+}
+
+// setDiffContent puts a loaded diff into the preview.
+func (m *Model) setDiffContent(key previewKey, content previewContent) {
+	m.previewViewport.SetContent(strings.Join(content.lines, "\n"))
+	m.previewShown = key
+	m.previewPlain = content.plain
+	m.refreshPreviewMatches()
+	m.diffBase = key.diffBase
+	m.diffLabel = content.label
+	m.diffLineMap = content.lineMap
+}
+
+// renderDiff renders a unified diff of the file against base. The lineMap
+// of the result records the file line each row shows, so the editor can be
+// opened where the preview is.
+func renderDiff(theme Theme, label, path, base, current string) previewContent {
+	diff := config.UnifiedDiff(label, path, base, current, 3)
+	if diff == "" {
+		return previewContent{lines: []string{lipgloss.NewStyle().Foreground(theme.Success).Render("✓ No differences from " + label)}, label: label}
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	hunkStyle := lipgloss.NewStyle().Foreground(theme.Accent)
+	deleteStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	insertStyle := lipgloss.NewStyle().Foreground(theme.Success)
+
+	content := previewContent{label: label}
+	newLine := 0
+	for i, line := range config.SplitLines(diff) {
+		content.plain = append(content.plain, line)
+		switch {
+		case i < config.UnifiedDiffHeaderLines:
+			content.lines = append(content.lines, headerStyle.Render(line))
+			content.lineMap = append(content.lineMap, 0)
+		case strings.HasPrefix(line, "@@"):
+			var oldStart, oldCount int
+			fmt.Sscanf(line, "@@ -%d,%d +%d", &oldStart, &oldCount, &newLine)
+			content.lines = append(content.lines, hunkStyle.Render(line))
+			content.lineMap = append(content.lineMap, newLine)
+		case strings.HasPrefix(line, "-"):
+			content.lines = append(content.lines, deleteStyle.Render(line))
+			content.lineMap = append(content.lineMap, max(newLine, 1))
+		case strings.HasPrefix(line, "+"):
+			content.lines = append(content.lines, insertStyle.Render(line))
+			content.lineMap = append(content.lineMap, newLine)
+			newLine++
+		default:
+			content.lines = append(content.lines, line)
+			content.lineMap = append(content.lineMap, newLine)
+			newLine++
+		}
+	}
+	return content
+}
@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRenderDiffDeletedCommentLine(t *testing.T) {
+	base := "local a = 1\n-- comment\nlocal b = 2\nlocal c = 3\n"
+	current := "local a = 1\nlocal b = 2\nlocal c = 3\n"
+
+	content := renderDiff(MonochromeTheme(), "base", "init.lua", base, current)
+
+	wantPlain := []string{
+		"--- base",
+		"+++ init.lua",
+		"@@ -1,4 +1,3 @@",
+		" local a = 1",
+		"--- comment",
+		" local b = 2",
+		" local c = 3",
+	}
+	if !reflect.DeepEqual(content.plain, wantPlain) {
+		t.Fatalf("plain = %q, want %q", content.plain, wantPlain)
+	}
+	wantLineMap := []int{0, 0, 1, 1, 2, 2, 3}
+	if !reflect.DeepEqual(content.lineMap, wantLineMap) {
+		t.Errorf("lineMap = %v, want %v", content.lineMap, wantLineMap)
+	}
+}
@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"hydectl/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxStatusLines bounds the edit output kept in the status pane.
+const maxStatusLines = 6
+
+// previewCursor is a line the preview was jumped to, tied to the file shown
+// at the time.
+type previewCursor struct {
+	file string
+	line int
+}
+
+type editFinishedMsg struct {
+	app    string
+	file   string
+	output string
+	err    error
+}
+
+// editProcess runs a full edit, hooks included, while the TUI is suspended.
+// The output of the edit is recorded so it can be shown once the TUI is back.
+type editProcess struct {
+	app        string
+	file       string
+	fileConfig config.ConfigFile
+	line       int
+	stdout     io.Writer
+	output     bytes.Buffer
+}
+
+func (e *editProcess) SetStdin(io.Reader) {}
+
+func (e *editProcess) SetStdout(w io.Writer) {
+	if w != nil {
+		e.stdout = w
+	}
+}
+
+func (e *editProcess) SetStderr(io.Writer) {}
+
+func (e *editProcess) Run() error {
+	previous := config.Output
+	config.Output = io.MultiWriter(e.stdout, &e.output)
+	defer func() { config.Output = previous }()
+
+	config.EditConfigFileAt(e.app, e.file, e.fileConfig, e.line)
+	return nil
+}
+
+// editFile suspends the TUI and edits fileName, opening the editor at line
+// when it is greater than zero.
+func (m *Model) editFile(fileName string, line int) tea.Cmd {
+	app, file := m.fileTarget(fileName)
+	proc := &editProcess{
+		app:        app,
+		file:       file,
+		fileConfig: m.registry.Apps[app].ResolveFile(file),
+		line:       line,
+		stdout:     os.Stdout,
+	}
+	return tea.Exec(proc, func(err error) tea.Msg {
+		return editFinishedMsg{app: proc.app, file: proc.file, output: proc.output.String(), err: err}
+	})
+}
+
+// previewLine returns the file line the preview is at: the line jumped to or
+// the current search match when it is still on screen, otherwise the top
+// visible line. It is zero while the preview has not been moved. In diff
+// mode it is the first file line shown in the diff.
+func (m *Model) previewLine() int {
+	top := m.previewViewport.YOffset
+	if m.diffBase != "" {
+		for row := top; row < len(m.diffLineMap) && row < top+m.previewViewport.Height; row++ {
+			if m.diffLineMap[row] > 0 {
+				return m.diffLineMap[row]
+			}
+		}
+		return 0
+	}
+	cursor := m.previewCursor.line
+	if m.previewCursor.file == m.currentPreviewFile() && cursor > top && cursor <= top+m.previewViewport.Height {
+		return cursor
+	}
+	if top == 0 {
+		return 0
+	}
+	return top + 1
+}
+
+// setPreviewCursor remembers the line the preview was moved to.
+func (m *Model) setPreviewCursor(line int) {
+	m.previewCursor = previewCursor{file: m.currentPreviewFile(), line: line}
+}
+
+func (m *Model) currentPreviewFile() string {
+	if len(m.fileList) == 0 || m.activeFileTab >= len(m.fileList) {
+		return ""
+	}
+	app, file := m.fileTarget(m.fileList[m.activeFileTab])
+	return app + "/" + file
+}
+
+func (m *Model) handleEditFinished(msg editFinishedMsg) {
+	m.reloadRecent()
+	m.checkFileExists()
+	if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
+		m.updatePreview(m.fileList[m.activeFileTab])
+	}
+
+	var lines []string
+	for _, line := range strings.Split(msg.output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, stripANSI(line))
+		}
+	}
+	if msg.err != nil {
+		lines = append(lines, "❌ "+msg.err.Error())
+	}
+	if len(lines) > maxStatusLines {
+		lines = lines[len(lines)-maxStatusLines:]
+	}
+	m.editStatus = lines
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
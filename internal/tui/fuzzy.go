@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Fuzzy match scoring, modelled on fzf: every matched character scores, with
+// bonuses for matches at word boundaries and for runs of consecutive
+// characters, and penalties for the gaps between matches.
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+	fuzzyBonusBoundary     = 8
+	fuzzyBonusCamel        = 6
+	fuzzyBonusConsecutive  = 6
+	fuzzyBonusFirstChar    = 2
+)
+
+// fuzzyMatch scores text against pattern, matching the pattern's characters
+// in order and case-insensitively. It returns the rune positions of the best
+// alignment, and false when text does not contain the pattern.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(p) > len(t) || len(lower) != len(t) {
+		return 0, nil, false
+	}
+
+	const none = -1 << 30
+	n, m := len(p), len(t)
+	// score[i][j] is the best score with p[i] matched at t[j]; from[i][j]
+	// records where p[i-1] was matched for that score.
+	score := make([][]int, n)
+	from := make([][]int, n)
+	for i := range score {
+		score[i] = make([]int, m)
+		from[i] = make([]int, m)
+		for j := range score[i] {
+			score[i][j] = none
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		best, bestAt := none, -1
+		for j := i; j < m; j++ {
+			if i > 0 && j >= 2 {
+				// Extend the gap behind j by one, or start it at j-2.
+				if best != none {
+					best += fuzzyScoreGapExtension
+				}
+				if prev := score[i-1][j-2]; prev != none && prev+fuzzyScoreGapStart > best {
+					best, bestAt = prev+fuzzyScoreGapStart, j-2
+				}
+			}
+			if lower[j] != p[i] {
+				continue
+			}
+
+			bonus := fuzzyBonus(t, j)
+			if i == 0 {
+				score[i][j] = fuzzyScoreMatch + bonus*fuzzyBonusFirstChar
+				continue
+			}
+			if prev := score[i-1][j-1]; prev != none {
+				score[i][j] = prev + fuzzyScoreMatch + max(bonus, fuzzyBonusConsecutive)
+				from[i][j] = j - 1
+			}
+			if best != none && best+fuzzyScoreMatch+bonus > score[i][j] {
+				score[i][j] = best + fuzzyScoreMatch + bonus
+				from[i][j] = bestAt
+			}
+		}
+	}
+
+	end, total := -1, none
+	for j := n - 1; j < m; j++ {
+		if score[n-1][j] > total {
+			end, total = j, score[n-1][j]
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		positions[i] = end
+		end = from[i][end]
+	}
+	return total, positions, true
+}
+
+func fuzzyBonus(t []rune, j int) int {
+	if j == 0 {
+		return fuzzyBonusBoundary
+	}
+	prev, cur := t[j-1], t[j]
+	switch {
+	case strings.ContainsRune("/-_. :", prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyBonusCamel
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev) && (unicode.IsLetter(cur) || unicode.IsDigit(cur)):
+		return fuzzyBonusBoundary
+	}
+	return 0
+}
+
+// fuzzyMatchAny returns the best match of pattern over several fields. index
+// is the field that matched best, or -1 when none did.
+func fuzzyMatchAny(pattern string, fields ...string) (score int, positions []int, index int) {
+	index = -1
+	for i, field := range fields {
+		if field == "" {
+			continue
+		}
+		s, pos, ok := fuzzyMatch(pattern, field)
+		if ok && (index < 0 || s > score) {
+			score, positions, index = s, pos, i
+		}
+	}
+	return score, positions, index
+}
+
+// highlightMatches renders text with the runes at positions in matchStyle
+// and the rest in baseStyle.
+func highlightMatches(text string, positions []int, baseStyle, matchStyle lipgloss.Style) string {
+	if len(positions) == 0 {
+		return baseStyle.Render(text)
+	}
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(matchStyle.Render(string(run)))
+		} else {
+			b.WriteString(baseStyle.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(text) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
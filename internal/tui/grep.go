@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"hydectl/internal/config"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openGrep starts a content search across every registered file.
+func (m *Model) openGrep() {
+	m.grepMode = true
+	m.grepInput = true
+	m.focusArea = PreviewFocus
+	m.grepQuery = ""
+	m.grepResults = nil
+	m.grepIndex = 0
+}
+
+func (m *Model) closeGrep() {
+	m.grepMode = false
+	m.grepInput = false
+	m.grepResults = nil
+}
+
+func (m *Model) handleGrepMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.grepInput {
+		switch {
+		case key.Matches(msg, m.keys.Confirm):
+			if m.grepQuery == "" {
+				return m, nil
+			}
+			m.grepResults = m.registry.Grep(config.CompileGrepPattern(m.grepQuery))
+			m.grepIndex = 0
+			m.grepInput = false
+		case key.Matches(msg, m.keys.Cancel):
+			m.closeGrep()
+		case msg.Type == tea.KeyBackspace:
+			m.grepQuery = dropLastRune(m.grepQuery)
+		default:
+			if len(msg.Runes) > 0 {
+				m.grepQuery += string(msg.Runes)
+			}
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.grepIndex > 0 {
+			m.grepIndex--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.grepIndex < len(m.grepResults)-1 {
+			m.grepIndex++
+		}
+	case key.Matches(msg, m.keys.PageUp):
+		m.grepIndex = max(m.grepIndex-m.grepRows(), 0)
+	case key.Matches(msg, m.keys.PageDown):
+		m.grepIndex = max(min(m.grepIndex+m.grepRows(), len(m.grepResults)-1), 0)
+	case key.Matches(msg, m.keys.Confirm):
+		if m.grepIndex < len(m.grepResults) {
+			m.jumpToGrepMatch(m.grepResults[m.grepIndex])
+		}
+	case key.Matches(msg, m.keys.Grep, m.keys.Search):
+		m.grepInput = true
+	case key.Matches(msg, m.keys.Cancel, m.keys.Quit):
+		m.closeGrep()
+	}
+	return m, nil
+}
+
+// jumpToGrepMatch selects the app and file of a match and shows its line in
+// the preview, with the search pattern highlighted.
+func (m *Model) jumpToGrepMatch(match config.GrepMatch) {
+	query := m.grepQuery
+	m.closeGrep()
+
+	for i, app := range m.appList {
+		if app == match.App {
+			m.activeAppTab = i
+			m.expandAppTab(i)
+			break
+		}
+	}
+	for i, file := range m.fileList {
+		if file == match.File {
+			m.activeFileTab = i
+			break
+		}
+	}
+
+	// The match is a line of the file, so leave the diff view to show it.
+	m.diffBase = ""
+	m.focusArea = PreviewFocus
+	m.searchActive = true
+	m.previewSearchBuffer = query
+	m.previewScrollTo = match.Line
+	m.updatePreview(match.File)
+}
+
+// grepRows is the number of results visible at once.
+func (m *Model) grepRows() int {
+	return max(m.mainHeight()-5, 1)
+}
+
+func (m *Model) renderGrepColumn(width, height int) string {
+	locationStyle := lipgloss.NewStyle().Foreground(m.theme.Highlight)
+	dimStyle := lipgloss.NewStyle().Foreground(m.theme.Dim)
+
+	content := []string{
+		m.styles.columnHeader.Render("🔍 Search in files"),
+		strings.Repeat("─", width-2),
+	}
+
+	switch {
+	case m.grepInput:
+		content = append(content, fmt.Sprintf("🔍 %s█", m.grepQuery), "")
+	case len(m.grepResults) == 0:
+		content = append(content, dimStyle.Render(fmt.Sprintf("No matches for %q", m.grepQuery)), "")
+	default:
+		content = append(content, dimStyle.Render(fmt.Sprintf("%d match(es) for %q", len(m.grepResults), m.grepQuery)), "")
+	}
+
+	if !m.grepInput {
+		rows := m.grepRows()
+		start := max(min(m.grepIndex-rows/2, len(m.grepResults)-rows), 0)
+		end := min(start+rows, len(m.grepResults))
+		lineStyle := lipgloss.NewStyle().MaxWidth(width - 2)
+		for i := start; i < end; i++ {
+			match := m.grepResults[i]
+			location := fmt.Sprintf("%s/%s:%d", match.App, match.File, match.Line)
+			text := strings.TrimSpace(match.Text)
+			var row string
+			if i == m.grepIndex {
+				row = m.styles.activeFile.Render(location + "  " + text)
+			} else {
+				row = " " + locationStyle.Render(location) + "  " + text
+			}
+			content = append(content, lineStyle.Render(row))
+		}
+	}
+
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(content, "\n"))
+}
+
+// dropLastRune removes the last character typed into a query.
+func dropLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
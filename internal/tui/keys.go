@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// KeyMap holds the TUI's key bindings. Each binding can be overridden by
+// action name from the [keys] table of hydectl's config file.
+type KeyMap struct {
+	Up             key.Binding
+	Down           key.Binding
+	Left           key.Binding
+	Right          key.Binding
+	PageUp         key.Binding
+	PageDown       key.Binding
+	Top            key.Binding
+	Bottom         key.Binding
+	GotoPrefix     key.Binding
+	FocusNext      key.Binding
+	FocusPrev      key.Binding
+	Select         key.Binding
+	Confirm        key.Binding
+	Cancel         key.Binding
+	Search         key.Binding
+	Grep           key.Binding
+	NextMatch      key.Binding
+	PrevMatch      key.Binding
+	Diff           key.Binding
+	Pin            key.Binding
+	LineNumbers    key.Binding
+	HighlightStyle key.Binding
+	Debug          key.Binding
+	Help           key.Binding
+	Quit           key.Binding
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:             key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+		Down:           key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+		Left:           key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "back / scroll left")),
+		Right:          key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "forward / scroll right")),
+		PageUp:         key.NewBinding(key.WithKeys("pgup"), key.WithHelp("pgup", "page up")),
+		PageDown:       key.NewBinding(key.WithKeys("pgdown"), key.WithHelp("pgdown", "page down")),
+		Top:            key.NewBinding(key.WithKeys("home"), key.WithHelp("home/gg", "top")),
+		Bottom:         key.NewBinding(key.WithKeys("G", "end"), key.WithHelp("G/end", "bottom")),
+		GotoPrefix:     key.NewBinding(key.WithKeys("g"), key.WithHelp("g<n>", "go to line n")),
+		FocusNext:      key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next pane")),
+		FocusPrev:      key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous pane")),
+		Select:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open / edit")),
+		Confirm:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
+		Cancel:         key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "cancel")),
+		Search:         key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		Grep:           key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search in files")),
+		NextMatch:      key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+		PrevMatch:      key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous match")),
+		Diff:           key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "diff")),
+		Pin:            key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin / unpin")),
+		LineNumbers:    key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "line numbers")),
+		HighlightStyle: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "highlight style")),
+		Debug:          key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "debug")),
+		Help:           key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
+		Quit:           key.NewBinding(key.WithKeys("q", "ctrl+c", "esc"), key.WithHelp("q", "quit")),
+	}
+}
+
+// actions maps the names used in the config file to the bindings.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":              &k.Up,
+		"down":            &k.Down,
+		"left":            &k.Left,
+		"right":           &k.Right,
+		"page_up":         &k.PageUp,
+		"page_down":       &k.PageDown,
+		"top":             &k.Top,
+		"bottom":          &k.Bottom,
+		"goto_line":       &k.GotoPrefix,
+		"focus_next":      &k.FocusNext,
+		"focus_prev":      &k.FocusPrev,
+		"select":          &k.Select,
+		"confirm":         &k.Confirm,
+		"cancel":          &k.Cancel,
+		"search":          &k.Search,
+		"grep":            &k.Grep,
+		"next_match":      &k.NextMatch,
+		"prev_match":      &k.PrevMatch,
+		"diff":            &k.Diff,
+		"pin":             &k.Pin,
+		"line_numbers":    &k.LineNumbers,
+		"highlight_style": &k.HighlightStyle,
+		"debug":           &k.Debug,
+		"help":            &k.Help,
+		"quit":            &k.Quit,
+	}
+}
+
+// keyGroups lists actions that are handled at the same time and so must not
+// share a key: those of the main view, and those of the pickers and prompts.
+var keyGroups = [][]string{
+	{"up", "down", "left", "right", "page_up", "page_down", "top", "bottom", "goto_line", "focus_next", "focus_prev",
+		"select", "search", "grep", "next_match", "prev_match", "diff", "pin", "line_numbers", "highlight_style", "debug", "help", "quit"},
+	{"up", "down", "page_up", "page_down", "top", "bottom", "confirm", "cancel", "search", "grep", "highlight_style"},
+}
+
+// NewKeyMap returns the default key bindings with overrides applied. An
+// override replaces all keys of an action, and its help shows the new keys.
+// Unknown actions, actions without keys and keys bound to two actions that
+// are active at the same time are all reported, one per line.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	actions := keys.actions()
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		binding, ok := actions[name]
+		if !ok {
+			valid := make([]string, 0, len(actions))
+			for action := range actions {
+				valid = append(valid, action)
+			}
+			sort.Strings(valid)
+			errs = append(errs, fmt.Errorf("unknown key action %q, expected one of: %s", name, strings.Join(valid, ", ")))
+			continue
+		}
+		if len(overrides[name]) == 0 {
+			errs = append(errs, fmt.Errorf("key action %q has no keys", name))
+			continue
+		}
+		binding.SetKeys(overrides[name]...)
+		binding.SetHelp(strings.Join(overrides[name], "/"), binding.Help().Desc)
+	}
+
+	reported := make(map[string]bool)
+	for _, group := range keyGroups {
+		owners := make(map[string]string)
+		for _, name := range group {
+			for _, k := range actions[name].Keys() {
+				owner, taken := owners[k]
+				if !taken {
+					owners[k] = name
+					continue
+				}
+				if conflict := owner + " " + name + " " + k; !reported[conflict] {
+					reported[conflict] = true
+					errs = append(errs, fmt.Errorf("key %q is bound to both %s and %s", k, owner, name))
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return KeyMap{}, errors.Join(errs...)
+	}
+	return keys, nil
+}
+
+// footerBindings returns the bindings shown in the footer for the current
+// mode and focused pane.
+func (m *Model) footerBindings() []key.Binding {
+	k := m.keys
+	switch {
+	case m.showHelp:
+		return []key.Binding{k.Help, k.Cancel}
+	case m.stylePicker:
+		return []key.Binding{k.Up, k.Down, k.Confirm, k.Cancel}
+	case m.grepMode && m.grepInput:
+		return []key.Binding{k.Confirm, k.Cancel}
+	case m.grepMode:
+		return []key.Binding{k.Up, k.Down, k.Confirm, k.Search, k.Cancel}
+	case m.searchMode:
+		return []key.Binding{k.Confirm, k.Cancel}
+	}
+
+	var bindings []key.Binding
+	switch m.focusArea {
+	case AppTabsFocus:
+		bindings = []key.Binding{k.Up, k.Down, k.Select}
+	case FileTrayFocus:
+		bindings = []key.Binding{k.Up, k.Down, k.Select, k.Diff, k.Pin}
+	case PreviewFocus:
+		bindings = []key.Binding{k.PageUp, k.PageDown, k.Select, k.Diff, k.LineNumbers}
+	}
+	return append(bindings, k.FocusNext, k.Search, k.Grep, k.Debug, k.Help, k.Quit)
+}
+
+// fullHelp returns every binding, grouped into the columns of the help
+// overlay.
+func (k KeyMap) fullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Left, k.Right, k.FocusNext, k.FocusPrev},
+		{k.PageUp, k.PageDown, k.Top, k.Bottom, k.GotoPrefix, k.LineNumbers, k.HighlightStyle},
+		{k.Select, k.Search, k.NextMatch, k.PrevMatch, k.Grep, k.Diff, k.Pin},
+		{k.Confirm, k.Cancel, k.Debug, k.Help, k.Quit},
+	}
+}
+
+// newHelp returns a help view drawn in the theme's colors.
+func newHelp(theme Theme) help.Model {
+	h := help.New()
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Accent)
+	descStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	sepStyle := lipgloss.NewStyle().Foreground(theme.Subtle)
+	h.Styles.ShortKey, h.Styles.FullKey = keyStyle, keyStyle
+	h.Styles.ShortDesc, h.Styles.FullDesc = descStyle, descStyle
+	h.Styles.ShortSeparator, h.Styles.FullSeparator = sepStyle, sepStyle
+	return h
+}
@@ -3,6 +3,7 @@ package tui
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"os"
 	"regexp"
 	"sort"
@@ -12,11 +13,13 @@ import (
 	"fmt"
 
 	"hydectl/internal/config"
+	"hydectl/internal/highlight"
 
 	chroma "github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/quick"
+	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -53,30 +56,73 @@ type Model struct {
 	searchActive  bool
 	filteredApps  []string
 	filteredFiles []string
+	appMatches    map[string][]int
+	fileMatches   map[string][]int
+	appBestFile   map[string]string
 
-	quitting     bool
-	selectedFile string
-	currentApp   string
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	theme    Theme
+	styles   viewStyles
+
+	quitting   bool
+	currentApp string
 
 	previewViewport  viewport.Model
 	fileTrayViewport viewport.Model
 
+	previewCache    *previewCache
+	previewSeq      int
+	previewLoading  *previewLoad
+	previewCmd      tea.Cmd
+	previewShown    previewKey
+	previewPlain    []string
+	previewScrollTo int
+
 	lastScrollTime time.Time
 
-	highlightStyle      string
+	highlightStyle string
+	stylePicker    bool
+	styleNames     []string
+	styleIndex     int
+	styleOriginal  string
+
+	recent config.RecentFiles
+	// previewMatchIndices are the 1-based lines of previewPlain matching
+	// the search.
 	previewMatchIndices []int
 	previewMatchIndex   int
 
 	jumpToLineMode  bool
 	jumpToLineInput string
+	previewCursor   previewCursor
 
 	previewSearchBuffer string
 	debug               bool
 	debugLog            []string
 	lineNumbers         bool
+
+	editStatus []string
+
+	diffBase    string
+	diffLabel   string
+	diffLineMap []int
+
+	watcher       *fileWatcher
+	openedAt      time.Time
+	modifiedFiles map[string]bool
+
+	grepMode    bool
+	grepInput   bool
+	grepQuery   string
+	grepResults []config.GrepMatch
+	grepIndex   int
 }
 
-func NewModel(registry *config.OrderedConfigRegistry, highlightStyle string, debug bool) *Model {
+// NewModel creates the config selector. A monochrome theme turns off syntax
+// highlighting along with the other colors.
+func NewModel(registry *config.OrderedConfigRegistry, keys KeyMap, theme Theme, highlightStyle string, debug bool) *Model {
 	apps := make([]string, len(registry.AppsOrder))
 	copy(apps, registry.AppsOrder)
 
@@ -86,6 +132,11 @@ func NewModel(registry *config.OrderedConfigRegistry, highlightStyle string, deb
 	trayVp := viewport.New(30, 20)
 	trayVp.YPosition = 0
 
+	keys.Debug.SetEnabled(debug)
+	if theme.Monochrome {
+		highlightStyle = ""
+	}
+
 	m := &Model{
 		registry:         registry,
 		appList:          apps,
@@ -101,20 +152,62 @@ func NewModel(registry *config.OrderedConfigRegistry, highlightStyle string, deb
 		previewWidth:     60,
 		previewViewport:  previewVp,
 		fileTrayViewport: trayVp,
+		keys:             keys,
+		help:             newHelp(theme),
+		theme:            theme,
+		styles:           newStyles(theme),
+		previewCache:     newPreviewCache(),
+		openedAt:         time.Now(),
+		modifiedFiles:    make(map[string]bool),
 		highlightStyle:   highlightStyle,
 		debug:            debug,
 		lineNumbers:      true,
 	}
 	m.logTuiDebug(fmt.Sprintf("Debug mode: %v", debug))
+
+	// Start on the Recent pseudo-app when there is one.
+	m.reloadRecent()
+	m.activeAppTab = 0
 	return m
 }
 
 func (m *Model) Init() tea.Cmd {
-	return nil
+	if m.expandedAppTab == -1 && len(m.appList) > 0 {
+		m.expandAppTab(0)
+	}
+
+	cmds := []tea.Cmd{m.takePreviewCmd()}
+	watcher, err := newFileWatcher(m.registry)
+	if err != nil {
+		m.logTuiDebug(fmt.Sprintf("[Init] File watcher unavailable: %v", err))
+	} else {
+		m.watcher = watcher
+		cmds = append(cmds, watcher.wait())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if load := m.takePreviewCmd(); load != nil {
+		cmd = tea.Batch(cmd, load)
+	}
+	return model, cmd
+}
+
+func (m *Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case previewLoadedMsg:
+		m.handlePreviewLoaded(msg)
+		return m, nil
+
+	case editFinishedMsg:
+		m.handleEditFinished(msg)
+		return m, nil
+
+	case filesChangedMsg:
+		return m, m.handleFilesChanged(msg)
+
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
@@ -192,43 +285,55 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.grepMode {
+			return m.handleGrepMode(msg)
+		}
+		if m.showHelp {
+			if key.Matches(msg, m.keys.Help, m.keys.Cancel) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		if m.stylePicker {
+			return m.handleStylePicker(msg)
+		}
 		if m.jumpToLineMode {
-
-			switch msg.String() {
-			case "enter":
+			switch {
+			case key.Matches(msg, m.keys.Confirm):
 				if m.jumpToLineInput != "" {
 					lineNum := 0
 					fmt.Sscanf(m.jumpToLineInput, "%d", &lineNum)
 					if lineNum > 0 {
 						m.previewViewport.GotoTop()
 						m.previewViewport.ScrollDown(lineNum - 1)
+						m.setPreviewCursor(lineNum)
 					}
 				}
 				m.jumpToLineMode = false
 				m.jumpToLineInput = ""
 				return m, nil
-			case "esc", "ctrl+c":
+			case key.Matches(msg, m.keys.Cancel):
 				m.jumpToLineMode = false
 				m.jumpToLineInput = ""
 				return m, nil
-			case "backspace":
+			case msg.Type == tea.KeyBackspace:
 				if len(m.jumpToLineInput) > 0 {
 					m.jumpToLineInput = m.jumpToLineInput[:len(m.jumpToLineInput)-1]
 				}
 				return m, nil
 			default:
-				if len(msg.String()) == 1 && msg.String()[0] >= '0' && msg.String()[0] <= '9' {
+				if isDigitKey(msg) {
 					m.jumpToLineInput += msg.String()
 				}
 				return m, nil
 			}
 		}
 
-		if m.searchActive && m.focusArea == PreviewFocus && (msg.String() == "n" || msg.String() == "N") {
+		if m.searchActive && m.focusArea == PreviewFocus && key.Matches(msg, m.keys.NextMatch, m.keys.PrevMatch) {
 			if len(m.previewMatchIndices) == 0 {
 				return m, nil
 			}
-			if msg.String() == "n" {
+			if key.Matches(msg, m.keys.NextMatch) {
 				m.previewMatchIndex = (m.previewMatchIndex + 1) % len(m.previewMatchIndices)
 			} else {
 				m.previewMatchIndex = (m.previewMatchIndex - 1 + len(m.previewMatchIndices)) % len(m.previewMatchIndices)
@@ -241,9 +346,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleSearchMode(msg)
 		}
 
-		if m.focusArea == PreviewFocus && !m.jumpToLineMode {
+		if m.focusArea == PreviewFocus {
 
-			if msg.String() == "g" {
+			if key.Matches(msg, m.keys.GotoPrefix) {
 				if m.lastScrollTime.IsZero() {
 					m.lastScrollTime = time.Now()
 					return m, nil
@@ -256,7 +361,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			if !m.lastScrollTime.IsZero() {
 
-				if len(msg.String()) == 1 && msg.String()[0] >= '0' && msg.String()[0] <= '9' {
+				if isDigitKey(msg) {
 					m.jumpToLineMode = true
 					m.jumpToLineInput = msg.String()
 					m.lastScrollTime = time.Time{}
@@ -266,59 +371,48 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.lastScrollTime = time.Time{}
 				}
 			}
-			switch msg.String() {
-			case "G":
-				m.previewViewport.GotoBottom()
-				return m, nil
-			case "home":
+			switch {
+			case key.Matches(msg, m.keys.Top):
 				m.previewViewport.GotoTop()
 				return m, nil
-			case "end":
+			case key.Matches(msg, m.keys.Bottom):
 				m.previewViewport.GotoBottom()
 				return m, nil
-			case "pgup":
+			case key.Matches(msg, m.keys.PageUp):
 				m.previewViewport.ScrollUp(m.previewViewport.Height)
 				return m, nil
-			case "pgdown":
+			case key.Matches(msg, m.keys.PageDown):
 				m.previewViewport.ScrollDown(m.previewViewport.Height)
 				return m, nil
 			}
 		}
 
-		if m.searchMode && m.focusArea == PreviewFocus {
-			switch msg.String() {
-			case "up", "k":
-				if len(m.previewMatchIndices) > 0 {
-					m.previewMatchIndex = (m.previewMatchIndex - 1 + len(m.previewMatchIndices)) % len(m.previewMatchIndices)
-					m.scrollPreviewToMatch()
-					return m, nil
-				}
-			case "down", "j":
-				if len(m.previewMatchIndices) > 0 {
-					m.previewMatchIndex = (m.previewMatchIndex + 1) % len(m.previewMatchIndices)
-					m.scrollPreviewToMatch()
-					return m, nil
-				}
-			}
-		}
-
-		switch msg.String() {
-		case "q", "ctrl+c", "esc":
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			m.quitting = true
+			if m.watcher != nil {
+				m.watcher.Close()
+			}
 			return m, tea.Quit
 
-		case "/":
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = true
+
+		case key.Matches(msg, m.keys.Grep):
+			m.openGrep()
+
+		case key.Matches(msg, m.keys.Search):
 			m.searchMode = true
 			m.searchQuery = ""
 			m.updateFilteredLists()
 
-		case "tab":
+		case key.Matches(msg, m.keys.FocusNext):
 			m.cycleFocus(1)
 
-		case "shift+tab":
+		case key.Matches(msg, m.keys.FocusPrev):
 			m.cycleFocus(-1)
 
-		case "left", "h":
+		case key.Matches(msg, m.keys.Left):
 
 			if m.focusArea == FileTrayFocus {
 				m.focusArea = AppTabsFocus
@@ -326,19 +420,33 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.previewViewport.ScrollLeft(1)
 			}
 
-		case "ctrl+d":
-			if m.debug {
-				if m.focusArea == DebugFocus {
-					m.focusArea = AppTabsFocus
-				} else {
-					m.focusArea = DebugFocus
-				}
+		case key.Matches(msg, m.keys.Debug):
+			if m.focusArea == DebugFocus {
+				m.focusArea = AppTabsFocus
+			} else {
+				m.focusArea = DebugFocus
+			}
+
+		case key.Matches(msg, m.keys.Diff):
+			if m.focusArea == FileTrayFocus || m.focusArea == PreviewFocus {
+				m.cycleDiffBase()
 			}
-		case "ctrl+l":
+
+		case key.Matches(msg, m.keys.HighlightStyle):
+			m.openStylePicker()
+
+		case key.Matches(msg, m.keys.Pin):
+			if m.focusArea == FileTrayFocus || m.focusArea == PreviewFocus {
+				m.togglePin()
+			}
+
+		case key.Matches(msg, m.keys.LineNumbers):
 			m.lineNumbers = !m.lineNumbers
-			m.updatePreview(m.fileList[m.activeFileTab])
+			if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
+				m.updatePreview(m.fileList[m.activeFileTab])
+			}
 
-		case "right", "l":
+		case key.Matches(msg, m.keys.Right):
 
 			if m.focusArea == AppTabsFocus && m.expandedAppTab != -1 {
 				m.focusArea = FileTrayFocus
@@ -348,10 +456,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.previewViewport.ScrollRight(1)
 			}
 
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.handleEnter()
 
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			if m.focusArea == AppTabsFocus {
 				if m.activeAppTab > 0 {
 					m.activeAppTab--
@@ -368,7 +476,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.previewViewport.ScrollUp(1)
 			}
 
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			if m.focusArea == AppTabsFocus {
 				if m.activeAppTab < len(m.appList)-1 {
 					m.activeAppTab++
@@ -405,7 +513,7 @@ func (m *Model) updateDimensions() {
 		m.previewWidth = 30
 	}
 
-	contentHeight := m.windowHeight - 8
+	contentHeight := m.mainHeight()
 	if contentHeight < 10 {
 		contentHeight = 10
 	}
@@ -416,6 +524,16 @@ func (m *Model) updateDimensions() {
 	m.fileTrayViewport.Height = contentHeight
 }
 
+// mainHeight is the height of the columns, leaving room for the header,
+// details bar, footer and the edit status pane when it is shown.
+func (m *Model) mainHeight() int {
+	height := m.windowHeight - 8
+	if len(m.editStatus) > 0 {
+		height -= len(m.editStatus) + 2
+	}
+	return height
+}
+
 func (m *Model) cycleFocus(direction int) {
 	areas := []FocusArea{AppTabsFocus}
 
@@ -445,6 +563,7 @@ func (m *Model) expandAppTab(appIndex int) {
 	if appIndex >= 0 && appIndex < len(m.appList) {
 		m.expandedAppTab = appIndex
 		m.currentApp = m.appList[appIndex]
+		m.editStatus = nil
 		m.loadFileList()
 		m.activeFileTab = 0
 
@@ -455,6 +574,14 @@ func (m *Model) loadFileList() {
 	if m.currentApp == "" {
 		return
 	}
+	if m.currentApp == recentApp {
+		m.fileList = m.recentFileList()
+		m.checkFileExists()
+		if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
+			m.updatePreview(m.fileList[m.activeFileTab])
+		}
+		return
+	}
 
 	appConfig := m.registry.Apps[m.currentApp]
 	var files []string
@@ -480,6 +607,13 @@ func (m *Model) checkFileExists() {
 	if m.currentApp == "" {
 		return
 	}
+	if m.currentApp == recentApp {
+		for _, fileName := range m.fileList {
+			fileConfig, _ := m.lookupFile(fileName)
+			m.fileExists[fileName] = fileConfig.FileExists()
+		}
+		return
+	}
 
 	appConfig := m.registry.Apps[m.currentApp]
 	for fileName, fileConfig := range appConfig.Files {
@@ -487,70 +621,40 @@ func (m *Model) checkFileExists() {
 	}
 }
 
-func (m *Model) highlightFileContent(displayName, realPath, content, styleName string) string {
-
-	lexer := lexers.Match(realPath)
-
+// highlightContent renders content with chroma for the terminal. It runs off
+// the UI goroutine, so instead of logging it returns a debug note, and it
+// stops early with ctx's error once ctx is cancelled.
+func highlightContent(ctx context.Context, displayName, realPath, language, content, styleName string) (string, string, error) {
+	lexer := highlight.Lexer(language, realPath, content)
 	if lexer == nil {
-		name := strings.ToLower(realPath)
-		if strings.Contains(name, "css") {
-			lexer = lexers.Get("css")
-		} else if strings.Contains(name, "toml") {
-			lexer = lexers.Get("toml")
-		} else if strings.Contains(name, "conf") || strings.Contains(name, "rc") {
-			lexer = lexers.Get("ini")
-		} else if strings.Contains(name, "json") {
-			lexer = lexers.Get("json")
-		} else if strings.Contains(name, "sh") || strings.Contains(name, "bash") || strings.Contains(name, "zsh") {
-			lexer = lexers.Get("bash")
-		} else if strings.Contains(name, "yaml") || strings.Contains(name, "yml") {
-			lexer = lexers.Get("yaml")
-		} else if strings.Contains(name, "lua") {
-			lexer = lexers.Get("lua")
-		} else if strings.Contains(name, "py") {
-			lexer = lexers.Get("python")
-		} else if strings.Contains(name, "js") {
-			lexer = lexers.Get("javascript")
-		} else if strings.Contains(name, "hypr") {
-			lexer = lexers.Get("ini")
-		}
+		return content, fmt.Sprintf("[highlightContent] No lexer found for %s (realPath: %s)", displayName, realPath), nil
 	}
 
-	if lexer == nil {
-		lexer = lexers.Analyse(content)
-	}
-	if lexer == nil {
-		m.logTuiDebug(fmt.Sprintf("[highlightFileContent] No lexer found for %s (realPath: %s)", displayName, realPath))
-		return content
-	}
+	// Unknown names get chroma's fallback style.
+	style := styles.Get(styleName)
+	styleUsed := style.Name
 
-	tryStyles := []string{styleName, "monokai", "github", "native", "dracula"}
-	var style *chroma.Style
-	var styleUsed string
-	for _, s := range tryStyles {
-		if s == "" {
-			continue
-		}
-		style = styles.Get(s)
-		if style != nil {
-			styleUsed = s
-			break
-		}
+	note := fmt.Sprintf("[highlightContent] File: %s | RealPath: %s | Lexer: %s | Style: %s", displayName, realPath, lexer.Config().Name, styleUsed)
+
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, content)
+	if err != nil {
+		return content, fmt.Sprintf("[highlightContent] Chroma error: %v", err), nil
 	}
-	if style == nil {
-		style = styles.Fallback
-		styleUsed = "fallback"
+	cancellable := func() chroma.Token {
+		if ctx.Err() != nil {
+			return chroma.EOF
+		}
+		return tokens()
 	}
 
-	m.logTuiDebug(fmt.Sprintf("[highlightFileContent] File: %s | RealPath: %s | Lexer: %s | Style: %s", displayName, realPath, lexer.Config().Name, styleUsed))
-
 	var buf bytes.Buffer
-	err := quick.Highlight(&buf, content, lexer.Config().Name, "terminal256", style.Name)
-	if err != nil {
-		m.logTuiDebug(fmt.Sprintf("[highlightFileContent] Chroma error: %v", err))
-		return content
+	if err := formatters.TTY256.Format(&buf, style, cancellable); err != nil {
+		return content, fmt.Sprintf("[highlightContent] Chroma error: %v", err), nil
 	}
-	return buf.String()
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), note, nil
 }
 
 func (m *Model) logTuiDebug(msg string) {
@@ -566,91 +670,124 @@ func (m *Model) logTuiDebug(msg string) {
 	f.WriteString("[" + timestamp + "] " + msg + "\n")
 }
 
+// updatePreview shows fileName in the preview. Highlighted contents are
+// cached; on a miss the preview is loaded in the background by a command
+// that Update hands to Bubble Tea, replacing any load still in flight.
 func (m *Model) updatePreview(fileName string) {
 	if m.currentApp == "" || fileName == "" {
 		return
 	}
 
-	appConfig := m.registry.Apps[m.currentApp]
-	fileConfig, exists := appConfig.Files[fileName]
+	fileConfig, exists := m.lookupFile(fileName)
 	if !exists {
 		return
 	}
 
-	var contentLines []string
-	if fileConfig.FileExists() {
-		expandedPath := config.ExpandPath(fileConfig.Path)
-		content, _ := m.readFileContent(expandedPath)
-		joined := strings.Join(content, "\n")
-		highlighted := m.highlightFileContent(fileName, expandedPath, joined, m.highlightStyle)
-		contentLines = strings.Split(highlighted, "\n")
-	} else {
-		contentLines = []string{}
+	if m.diffBase != "" {
+		m.showDiff(fileName, fileConfig)
+		return
+	}
+
+	key, ok := m.previewKeyFor(fileConfig)
+	if !ok {
+		m.cancelPreviewLoad()
+		m.setPreviewLines(key, previewContent{})
+		return
+	}
+	if content, ok := m.previewCache.get(key); ok {
+		m.cancelPreviewLoad()
+		m.setPreviewLines(key, content)
+		return
+	}
+	if m.previewLoading != nil && m.previewLoading.key == key {
+		return
 	}
 
+	m.cancelPreviewLoad()
+	m.previewSeq++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.previewLoading = &previewLoad{seq: m.previewSeq, key: key, cancel: cancel}
+	m.previewCmd = loadPreview(ctx, m.previewSeq, key, fileName, fileConfig, m.theme)
+
+	// Keep showing a file that is only being reloaded, to avoid flicker.
+	if m.previewShown.path != key.path {
+		m.previewShown = previewKey{}
+		m.previewViewport.SetContent(lipgloss.NewStyle().Foreground(m.theme.Dim).Render("⏳ Loading " + fileName + "..."))
+	}
+}
+
+// setPreviewLines puts highlighted lines into the preview viewport.
+func (m *Model) setPreviewLines(key previewKey, content previewContent) {
 	var finalContent string
 	if m.lineNumbers {
 		var b strings.Builder
-		for i, line := range contentLines {
+		for i, line := range content.lines {
 			b.WriteString(fmt.Sprintf("%4d │ %s\n", i+1, line))
 		}
 		finalContent = b.String()
 	} else {
-		finalContent = strings.Join(contentLines, "\n")
+		finalContent = strings.Join(content.lines, "\n")
 	}
 
 	m.previewViewport.SetContent(finalContent)
+	m.previewShown = key
+	m.previewPlain = content.plain
+	if m.previewScrollTo > 0 {
+		m.previewViewport.SetYOffset(m.previewScrollTo - 1)
+		m.setPreviewCursor(m.previewScrollTo)
+		m.previewScrollTo = 0
+	}
+	m.refreshPreviewMatches()
+}
+
+// refreshPreviewMatches finds the search matches in newly shown contents.
+func (m *Model) refreshPreviewMatches() {
 	m.previewMatchIndices = nil
 	m.previewMatchIndex = 0
 	if m.searchMode && m.focusArea == PreviewFocus && m.searchQuery != "" {
 		m.updatePreviewMatches()
 	} else if m.searchActive && m.focusArea == PreviewFocus && m.previewSearchBuffer != "" {
-		m.previewMatchIndices = regexAllIndices(m.previewViewport.View(), m.previewSearchBuffer)
+		m.previewMatchIndices = matchingLines(m.previewPlain, m.previewSearchBuffer)
 	}
 }
 
 func (m *Model) updatePreviewMatches() {
-	content := m.previewViewport.View()
 	m.previewMatchIndices = nil
 	m.previewMatchIndex = 0
 	if m.searchQuery == "" {
 		return
 	}
-	indices := regexAllIndices(content, m.searchQuery)
-	m.previewMatchIndices = indices
+	m.previewMatchIndices = matchingLines(m.previewPlain, m.searchQuery)
 }
 
-func regexAllIndices(text, pattern string) []int {
-	var indices []int
-	re, err := regexp.Compile("(?i)" + pattern)
+// searchPattern compiles a case-insensitive search, taking the query
+// literally when it is not a valid regular expression.
+func searchPattern(query string) *regexp.Regexp {
+	re, err := regexp.Compile("(?i)" + query)
 	if err != nil {
-
-		pattern = regexp.QuoteMeta(pattern)
-		re = regexp.MustCompile("(?i)" + pattern)
-	}
-	matches := re.FindAllStringIndex(text, -1)
-	for _, m := range matches {
-		indices = append(indices, m[0])
+		re = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
 	}
-	return indices
+	return re
 }
 
-func (m *Model) readFileContent(filePath string) ([]string, error) {
-
-	content, _ := m.readFilePreviewWithScroll(filePath)
-	return content, nil
+// matchingLines returns the 1-based numbers of the lines matching query.
+func matchingLines(lines []string, query string) []int {
+	re := searchPattern(query)
+	var matches []int
+	for i, line := range lines {
+		if re.MatchString(line) {
+			matches = append(matches, i+1)
+		}
+	}
+	return matches
 }
 
-func (m *Model) readFilePreviewWithScroll(filePath string) ([]string, int) {
+func readFilePreview(filePath string, theme Theme) ([]string, int) {
 	var lines []string
 
-	ColorBrightBlack := lipgloss.Color("240")
-	ColorBrightRed := lipgloss.Color("196")
-	ColorDim := lipgloss.Color("245")
-
-	sepStyle := lipgloss.NewStyle().Foreground(ColorBrightBlack)
-	errStyle := lipgloss.NewStyle().Foreground(ColorBrightRed).Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(ColorDim)
+	sepStyle := lipgloss.NewStyle().Foreground(theme.Subtle)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return []string{dimStyle.Render("File does not exist")}, 1
@@ -706,29 +843,43 @@ func (m *Model) handleEnter() (tea.Model, tea.Cmd) {
 		if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
 			fileName := m.fileList[m.activeFileTab]
 			if m.canSelectFile(fileName) {
-				m.selectedFile = fileName
-				return m, tea.Quit
+				return m, m.editFile(fileName, m.previewLine())
 			}
 			m.searchActive = true
 		}
+	case PreviewFocus:
+		if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
+			fileName := m.fileList[m.activeFileTab]
+			if m.canSelectFile(fileName) {
+				return m, m.editFile(fileName, m.previewLine())
+			}
+		}
 	}
 	return m, nil
 }
 
+// canSelectFile reports whether a file can be edited: it exists, or the edit
+// will create it from its template.
 func (m *Model) canSelectFile(fileName string) bool {
 	exists, found := m.fileExists[fileName]
-	return found && exists
+	return found && (exists || m.canCreateFromTemplate(fileName))
+}
+
+func (m *Model) canCreateFromTemplate(fileName string) bool {
+	fileConfig, ok := m.lookupFile(fileName)
+	return ok && fileConfig.HasTemplate()
 }
 
 func (m *Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
+	switch {
+	case key.Matches(msg, m.keys.Confirm):
 		m.searchMode = false
 		m.searchActive = true
 		if m.focusArea == PreviewFocus && m.searchQuery != "" {
 			m.previewSearchBuffer = m.searchQuery
 		}
 		if m.focusArea == AppTabsFocus && len(m.filteredApps) > 0 {
+			bestFile := m.appBestFile[m.filteredApps[0]]
 			for i, app := range m.appList {
 				if app == m.filteredApps[0] {
 					m.activeAppTab = i
@@ -737,6 +888,13 @@ func (m *Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
+			for i, file := range m.fileList {
+				if file == bestFile {
+					m.activeFileTab = i
+					m.updatePreview(file)
+					break
+				}
+			}
 		} else if m.focusArea == FileTrayFocus && len(m.filteredFiles) > 0 {
 			for i, file := range m.fileList {
 				if file == m.filteredFiles[0] {
@@ -748,95 +906,129 @@ func (m *Model) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.searchQuery = ""
 		m.updateFilteredLists()
-	case "esc", "ctrl+c":
+	case key.Matches(msg, m.keys.Cancel):
 		m.searchMode = false
 		m.searchActive = false
 		m.searchQuery = ""
 		m.updateFilteredLists()
-	case "backspace":
+	case msg.Type == tea.KeyBackspace:
 		if len(m.searchQuery) > 0 {
-			m.searchQuery = m.searchQuery[:len(m.searchQuery)-1]
+			m.searchQuery = dropLastRune(m.searchQuery)
 			m.updateFilteredLists()
+			if m.focusArea == PreviewFocus {
+				m.updatePreviewMatches()
+			}
 			return m, nil
 		}
-	case "up", "k":
+	case key.Matches(msg, m.keys.Up):
 		if m.focusArea == PreviewFocus && len(m.previewMatchIndices) > 0 {
 			m.previewMatchIndex = (m.previewMatchIndex - 1 + len(m.previewMatchIndices)) % len(m.previewMatchIndices)
 			m.scrollPreviewToMatch()
 			return m, nil
 		}
-	case "down", "j":
+		m.appendSearchRunes(msg)
+	case key.Matches(msg, m.keys.Down):
 		if m.focusArea == PreviewFocus && len(m.previewMatchIndices) > 0 {
 			m.previewMatchIndex = (m.previewMatchIndex + 1) % len(m.previewMatchIndices)
 			m.scrollPreviewToMatch()
 			return m, nil
 		}
+		m.appendSearchRunes(msg)
 	default:
-		if len(msg.String()) == 1 {
-			m.searchQuery += msg.String()
-			m.updateFilteredLists()
-		}
+		m.appendSearchRunes(msg)
 	}
 
 	return m, nil
 }
 
+func isDigitKey(msg tea.KeyMsg) bool {
+	return len(msg.String()) == 1 && msg.String()[0] >= '0' && msg.String()[0] <= '9'
+}
+
+func (m *Model) appendSearchRunes(msg tea.KeyMsg) {
+	if msg.Type == tea.KeyRunes {
+		m.searchQuery += string(msg.Runes)
+		m.updateFilteredLists()
+		if m.focusArea == PreviewFocus {
+			m.updatePreviewMatches()
+		}
+	}
+}
+
+// updateFilteredLists ranks apps and files against the search query with
+// fuzzy matching, best match first. Apps also match through their files, so
+// "wbstyle" finds waybar by the path of its style.css.
 func (m *Model) updateFilteredLists() {
+	m.appMatches = nil
+	m.fileMatches = nil
+	m.appBestFile = nil
 	if !m.searchMode || m.searchQuery == "" {
 		m.filteredApps = m.appList
 		m.filteredFiles = m.fileList
 		return
 	}
 
-	query := strings.ToLower(m.searchQuery)
+	type ranked struct {
+		name  string
+		score int
+	}
+	sortRanked := func(items []ranked) []string {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].score > items[j].score })
+		names := make([]string, len(items))
+		for i, item := range items {
+			names[i] = item.name
+		}
+		return names
+	}
 
-	m.filteredApps = nil
+	m.appMatches = make(map[string][]int)
+	m.appBestFile = make(map[string]string)
+	var apps []ranked
 	for _, app := range m.appList {
+		if app == recentApp {
+			continue
+		}
 		appConfig := m.registry.Apps[app]
-		if strings.Contains(strings.ToLower(app), query) ||
-			strings.Contains(strings.ToLower(appConfig.Description), query) {
-			m.filteredApps = append(m.filteredApps, app)
+		score, positions, field := fuzzyMatchAny(m.searchQuery, app, appConfig.Description)
+		if field == 0 {
+			m.appMatches[app] = positions
+		}
+		for _, fileName := range appConfig.FileNames() {
+			fileConfig := appConfig.Files[fileName]
+			fileScore, _, fileField := fuzzyMatchAny(m.searchQuery, fileName, fileConfig.Description, fileConfig.Path)
+			if fileField >= 0 && (field < 0 || fileScore > score) {
+				score, field = fileScore, 2
+				m.appBestFile[app] = fileName
+			}
+		}
+		if field >= 0 {
+			apps = append(apps, ranked{app, score})
 		}
 	}
+	m.filteredApps = sortRanked(apps)
 
-	m.filteredFiles = nil
+	m.fileMatches = make(map[string][]int)
+	var files []ranked
 	if m.currentApp != "" {
 		for _, fileName := range m.fileList {
-			fileConfig := m.registry.Apps[m.currentApp].Files[fileName]
-			if strings.Contains(strings.ToLower(fileName), query) ||
-				strings.Contains(strings.ToLower(fileConfig.Description), query) ||
-				strings.Contains(strings.ToLower(fileConfig.Path), query) {
-				m.filteredFiles = append(m.filteredFiles, fileName)
+			fileConfig, _ := m.lookupFile(fileName)
+			score, positions, field := fuzzyMatchAny(m.searchQuery, fileName, fileConfig.Description, fileConfig.Path)
+			if field == 0 {
+				m.fileMatches[fileName] = positions
+			}
+			if field >= 0 {
+				files = append(files, ranked{fileName, score})
 			}
 		}
 	}
-}
-
-func (m *Model) GetSelectedApp() string {
-	return m.currentApp
-}
-
-func (m *Model) GetSelectedFile() string {
-	return m.selectedFile
-}
-
-func (m *Model) IsQuitting() bool {
-	return m.quitting
+	m.filteredFiles = sortRanked(files)
 }
 
 func (m *Model) scrollPreviewToMatch() {
 	if len(m.previewMatchIndices) == 0 {
 		return
 	}
-	pos := m.previewMatchIndices[m.previewMatchIndex]
-	content := m.previewViewport.View()
-
-	line := 0
-	for i := 0; i < pos && i < len(content); i++ {
-		if content[i] == '\n' {
-			line++
-		}
-	}
-	m.previewViewport.GotoTop()
-	m.previewViewport.ScrollDown(line)
+	line := m.previewMatchIndices[m.previewMatchIndex]
+	m.previewViewport.SetYOffset(line - 1)
+	m.setPreviewCursor(line)
 }
@@ -0,0 +1,184 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"hydectl/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// previewCacheSize bounds the number of highlighted files kept in memory.
+const previewCacheSize = 32
+
+// previewKey identifies highlighted preview contents. A change to the file
+// shows up as a new modification time or size.
+type previewKey struct {
+	path     string
+	modTime  time.Time
+	size     int64
+	template bool
+	language string
+	style    string
+	// diffBase is set for a diff of the file against that base, and
+	// baseVersion identifies the base's contents.
+	diffBase    string
+	baseVersion string
+}
+
+// previewContent is what the preview shows for a key. plain holds the same
+// rows without highlighting, for searching. Diffs also carry the label of
+// their base and the file line each row shows.
+type previewContent struct {
+	lines   []string
+	plain   []string
+	label   string
+	lineMap []int
+}
+
+type previewLoad struct {
+	seq    int
+	key    previewKey
+	cancel context.CancelFunc
+	// warnNoDiff shows a warning when a diff finds none of its bases.
+	warnNoDiff bool
+}
+
+type previewLoadedMsg struct {
+	seq     int
+	key     previewKey
+	content previewContent
+	note    string
+	err     error
+}
+
+type previewCache struct {
+	entries map[previewKey]previewContent
+	order   []previewKey
+}
+
+func newPreviewCache() *previewCache {
+	return &previewCache{entries: make(map[previewKey]previewContent)}
+}
+
+func (c *previewCache) get(key previewKey) (previewContent, bool) {
+	content, ok := c.entries[key]
+	return content, ok
+}
+
+// put stores content under key, dropping older versions of the same file and
+// the oldest entry once the cache is full.
+func (c *previewCache) put(key previewKey, content previewContent) {
+	kept := c.order[:0]
+	for _, k := range c.order {
+		if k.path == key.path && k.language == key.language && k.style == key.style && k.diffBase == key.diffBase {
+			delete(c.entries, k)
+			continue
+		}
+		kept = append(kept, k)
+	}
+	c.order = kept
+
+	if len(c.order) >= previewCacheSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = content
+	c.order = append(c.order, key)
+}
+
+// previewKeyFor returns the cache key of a file's preview. It reports false
+// when there is nothing to load: the file is missing and has no template.
+func (m *Model) previewKeyFor(fileConfig config.ConfigFile) (previewKey, bool) {
+	path := config.ExpandPath(fileConfig.Path)
+	key := previewKey{path: path, language: fileConfig.HighlightLanguage(), style: m.highlightStyle}
+	if info, err := os.Stat(path); err == nil {
+		key.modTime, key.size = info.ModTime(), info.Size()
+		return key, true
+	}
+	if fileConfig.HasTemplate() {
+		key.template = true
+		return key, true
+	}
+	return key, false
+}
+
+func (m *Model) cancelPreviewLoad() {
+	if m.previewLoading != nil {
+		m.previewLoading.cancel()
+		m.previewLoading = nil
+	}
+	m.previewCmd = nil
+}
+
+// takePreviewCmd returns the load queued by updatePreview, if any.
+func (m *Model) takePreviewCmd() tea.Cmd {
+	cmd := m.previewCmd
+	m.previewCmd = nil
+	return cmd
+}
+
+// loadPreview reads and highlights a file in the background. An empty style
+// leaves the contents unhighlighted.
+func loadPreview(ctx context.Context, seq int, key previewKey, fileName string, fileConfig config.ConfigFile, theme Theme) tea.Cmd {
+	return func() tea.Msg {
+		var content string
+		if key.template {
+			template, err := fileConfig.TemplateContent()
+			if err != nil {
+				template = err.Error()
+			}
+			content = template
+		} else {
+			lines, _ := readFilePreview(key.path, theme)
+			content = strings.Join(lines, "\n")
+		}
+		if err := ctx.Err(); err != nil {
+			return previewLoadedMsg{seq: seq, key: key, err: err}
+		}
+		plain := strings.Split(content, "\n")
+		if key.style == "" {
+			return previewLoadedMsg{seq: seq, key: key, content: previewContent{lines: plain, plain: plain}}
+		}
+
+		highlighted, note, err := highlightContent(ctx, fileName, key.path, key.language, content, key.style)
+		if err != nil {
+			return previewLoadedMsg{seq: seq, key: key, err: err}
+		}
+		return previewLoadedMsg{seq: seq, key: key, content: previewContent{lines: strings.Split(highlighted, "\n"), plain: plain}, note: note}
+	}
+}
+
+func (m *Model) handlePreviewLoaded(msg previewLoadedMsg) {
+	if errors.Is(msg.err, errNoDiffBase) {
+		m.handleNoDiffBase(msg.seq)
+		return
+	}
+	if msg.err != nil {
+		return
+	}
+	if msg.note != "" {
+		m.logTuiDebug(msg.note)
+	}
+	m.previewCache.put(msg.key, msg.content)
+
+	if m.previewLoading == nil || m.previewLoading.seq != msg.seq {
+		return
+	}
+	m.previewLoading.cancel()
+	m.previewLoading = nil
+	m.showPreviewContent(msg.key, msg.content)
+}
+
+// showPreviewContent puts loaded or cached contents into the preview.
+func (m *Model) showPreviewContent(key previewKey, content previewContent) {
+	if key.diffBase != "" {
+		m.setDiffContent(key, content)
+		return
+	}
+	m.setPreviewLines(key, content)
+}
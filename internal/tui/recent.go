@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"hydectl/internal/config"
+)
+
+// recentApp is the pseudo-app at the top of the app column that lists pinned
+// and recently edited files. Its file list entries are "app/file".
+const recentApp = "@recent"
+
+// fileTarget returns the app and file a file list entry refers to.
+func (m *Model) fileTarget(fileName string) (string, string) {
+	if m.currentApp == recentApp {
+		if app, file, ok := strings.Cut(fileName, "/"); ok {
+			return app, file
+		}
+	}
+	return m.currentApp, fileName
+}
+
+// lookupFile returns the registry entry of a file list entry.
+func (m *Model) lookupFile(fileName string) (config.ConfigFile, bool) {
+	app, file := m.fileTarget(fileName)
+	fileConfig, ok := m.registry.Apps[app].Files[file]
+	return fileConfig, ok
+}
+
+// recentFileList returns the pinned files followed by the recently edited
+// ones, skipping files no longer in the registry.
+func (m *Model) recentFileList() []string {
+	seen := make(map[string]bool)
+	var files []string
+	refs := append([]config.FileRef(nil), m.recent.Pinned...)
+	for _, edit := range m.recent.Recent {
+		refs = append(refs, edit.FileRef)
+	}
+	for _, ref := range refs {
+		name := ref.App + "/" + ref.File
+		if _, ok := m.registry.Apps[ref.App].Files[ref.File]; !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		files = append(files, name)
+	}
+	return files
+}
+
+// reloadRecent rereads the recent files, which edits update on disk.
+func (m *Model) reloadRecent() {
+	recent, err := config.LoadRecentFiles()
+	if err != nil {
+		m.logTuiDebug(fmt.Sprintf("[reloadRecent] %v", err))
+		return
+	}
+	m.recent = recent
+	m.syncRecentApp()
+}
+
+// syncRecentApp shows the Recent pseudo-app only while it has files, and
+// keeps the selection on the same app or file when its entries change.
+func (m *Model) syncRecentApp() {
+	files := m.recentFileList()
+	present := len(m.appList) > 0 && m.appList[0] == recentApp
+
+	switch {
+	case len(files) > 0 && !present:
+		m.appList = append([]string{recentApp}, m.appList...)
+		m.activeAppTab++
+		if m.expandedAppTab != -1 {
+			m.expandedAppTab++
+		}
+	case len(files) == 0 && present:
+		m.appList = m.appList[1:]
+		if m.currentApp == recentApp {
+			m.activeAppTab = 0
+			m.expandAppTab(0)
+			return
+		}
+		m.activeAppTab = max(m.activeAppTab-1, 0)
+		if m.expandedAppTab > 0 {
+			m.expandedAppTab--
+		}
+	}
+
+	if m.currentApp == recentApp {
+		selected := ""
+		if m.activeFileTab < len(m.fileList) {
+			selected = m.fileList[m.activeFileTab]
+		}
+		m.fileList = files
+		m.checkFileExists()
+		m.activeFileTab = min(m.activeFileTab, max(len(files)-1, 0))
+		for i, name := range files {
+			if name == selected {
+				m.activeFileTab = i
+				break
+			}
+		}
+	}
+}
+
+// togglePin pins or unpins the selected file.
+func (m *Model) togglePin() {
+	if len(m.fileList) == 0 || m.activeFileTab >= len(m.fileList) {
+		return
+	}
+	app, file := m.fileTarget(m.fileList[m.activeFileTab])
+	pinned := m.recent.TogglePin(app, file)
+	if err := m.recent.Save(); err != nil {
+		m.editStatus = []string{fmt.Sprintf("❌ Failed to save pinned files: %v", err)}
+		return
+	}
+	if pinned {
+		m.editStatus = []string{fmt.Sprintf("📌 Pinned %s/%s", app, file)}
+	} else {
+		m.editStatus = []string{fmt.Sprintf("Unpinned %s/%s", app, file)}
+	}
+	m.syncRecentApp()
+	if len(m.fileList) > 0 {
+		m.updatePreview(m.fileList[m.activeFileTab])
+	}
+}
+
+// isPinned reports whether a file list entry is pinned.
+func (m *Model) isPinned(fileName string) bool {
+	return m.recent.IsPinned(m.fileTarget(fileName))
+}
+
+// appInfo returns the icon, label and description an app is shown with.
+func (m *Model) appInfo(appName string) (string, string, string) {
+	if appName == recentApp {
+		return "🕘", "Recent", "Pinned and recently edited files"
+	}
+	appConfig := m.registry.Apps[appName]
+	return normalizeIcon(appConfig.Icon, "⚙️"), appName, appConfig.Description
+}
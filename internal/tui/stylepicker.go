@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"hydectl/internal/config"
+
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// settingHighlightStyle is the config file key the picked style is saved to.
+const settingHighlightStyle = "highlight_style"
+
+// openStylePicker lists the chroma styles in place of the app column,
+// starting at the one in use.
+func (m *Model) openStylePicker() {
+	if m.theme.Monochrome {
+		m.editStatus = []string{"⚠️  Syntax highlighting is off in monochrome mode"}
+		return
+	}
+	m.stylePicker = true
+	m.styleNames = styles.Names()
+	m.styleOriginal = m.highlightStyle
+	m.styleIndex = 0
+	for i, name := range m.styleNames {
+		if name == m.highlightStyle {
+			m.styleIndex = i
+			break
+		}
+	}
+}
+
+func (m *Model) handleStylePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	index := m.styleIndex
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		index--
+	case key.Matches(msg, m.keys.Down):
+		index++
+	case key.Matches(msg, m.keys.PageUp):
+		index -= m.stylePickerRows()
+	case key.Matches(msg, m.keys.PageDown):
+		index += m.stylePickerRows()
+	case key.Matches(msg, m.keys.Top):
+		index = 0
+	case key.Matches(msg, m.keys.Bottom):
+		index = len(m.styleNames) - 1
+	case key.Matches(msg, m.keys.Confirm):
+		m.stylePicker = false
+		if err := config.SaveSetting(settingHighlightStyle, m.highlightStyle); err != nil {
+			m.editStatus = []string{fmt.Sprintf("❌ Failed to save highlight style: %v", err)}
+		} else {
+			m.editStatus = []string{fmt.Sprintf("✅ Highlight style %q saved to %s", m.highlightStyle, config.SettingsPath())}
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Cancel, m.keys.HighlightStyle):
+		m.stylePicker = false
+		m.setHighlightStyle(m.styleOriginal)
+		return m, nil
+	}
+
+	index = max(min(index, len(m.styleNames)-1), 0)
+	if index != m.styleIndex {
+		m.styleIndex = index
+		m.setHighlightStyle(m.styleNames[index])
+	}
+	return m, nil
+}
+
+// setHighlightStyle re-renders the preview with another style.
+func (m *Model) setHighlightStyle(name string) {
+	if name == m.highlightStyle {
+		return
+	}
+	m.highlightStyle = name
+	if m.expandedAppTab != -1 && len(m.fileList) > 0 {
+		m.updatePreview(m.fileList[m.activeFileTab])
+	}
+}
+
+// stylePickerRows is the number of styles visible at once.
+func (m *Model) stylePickerRows() int {
+	return max(m.mainHeight()-2, 1)
+}
+
+func (m *Model) renderStylePicker() string {
+	content := []string{
+		m.styles.columnHeader.Render("🎨 Highlight style"),
+		strings.Repeat("─", m.tabWidth-2),
+	}
+
+	rows := m.stylePickerRows()
+	start := max(min(m.styleIndex-rows/2, len(m.styleNames)-rows), 0)
+	end := min(start+rows, len(m.styleNames))
+	for i := start; i < end; i++ {
+		name := m.styleNames[i]
+		if i == m.styleIndex {
+			content = append(content, m.styles.focusedTab.Render("▶ "+name))
+		} else {
+			content = append(content, m.styles.inactiveTab.Render("  "+name))
+		}
+	}
+
+	return lipgloss.NewStyle().Width(m.tabWidth).Height(m.mainHeight()).Render(strings.Join(content, "\n"))
+}
@@ -2,58 +2,83 @@ package tui
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-var (
-	headerStyle = lipgloss.NewStyle().
+// viewStyles are the lipgloss styles the view derives from a Theme.
+type viewStyles struct {
+	header       lipgloss.Style
+	columnHeader lipgloss.Style
+	activeTab    lipgloss.Style
+	inactiveTab  lipgloss.Style
+	focusedTab   lipgloss.Style
+	activeFile   lipgloss.Style
+	inactiveFile lipgloss.Style
+	missingFile  lipgloss.Style
+	focusedPane  lipgloss.Style
+	footer       lipgloss.Style
+	match        lipgloss.Style
+	currentMatch lipgloss.Style
+}
+
+func newStyles(theme Theme) viewStyles {
+	currentMatch := lipgloss.NewStyle().
+		Foreground(theme.OnAccent).
+		Background(theme.Highlight).
+		Bold(true)
+	if theme.Monochrome {
+		currentMatch = currentMatch.Reverse(true)
+	}
+
+	return viewStyles{
+		header: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("86")).
+			Foreground(theme.Title).
 			Padding(0, 1).
 			Width(80).
-			Align(lipgloss.Center)
-
-	activeTabStyle = lipgloss.NewStyle().
+			Align(lipgloss.Center),
+		columnHeader: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("86"))
-
-	inactiveTabStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("244"))
-
-	focusedTabStyle = lipgloss.NewStyle().
+			Foreground(theme.Accent),
+		activeTab: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(theme.Title),
+		inactiveTab: lipgloss.NewStyle().
+			Foreground(theme.Muted),
+		focusedTab: lipgloss.NewStyle().
 			Bold(true).
 			Underline(true).
-			Foreground(lipgloss.Color("51"))
-
-	activeFileStyle = lipgloss.NewStyle().
+			Foreground(theme.Accent),
+		activeFile: lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("226")).
-			Padding(0, 1)
-
-	inactiveFileStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("15")).
-				Padding(0, 1)
-
-	missingFileStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("244")).
-				Padding(0, 1)
-
-	columnStyle = lipgloss.NewStyle().
-			Width(0).
-			Height(0)
-
-	focusedColumnStyle = lipgloss.NewStyle().
-				Width(0).
-				Height(0).
-				Bold(true)
-
-	footerStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("244")).
-			Padding(0, 1)
-)
+			Foreground(theme.Highlight).
+			Padding(0, 1),
+		inactiveFile: lipgloss.NewStyle().
+			Foreground(theme.Text).
+			Padding(0, 1),
+		missingFile: lipgloss.NewStyle().
+			Foreground(theme.Muted).
+			Padding(0, 1),
+		focusedPane: lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderRight(true).
+			BorderTop(false).
+			BorderBottom(false).
+			BorderForeground(theme.Accent).
+			Bold(true),
+		footer: lipgloss.NewStyle().
+			Foreground(theme.Muted).
+			Padding(0, 1),
+		match: lipgloss.NewStyle().
+			Foreground(theme.Highlight).
+			Bold(true).
+			Underline(true),
+		currentMatch: currentMatch,
+	}
+}
 
 func (m *Model) View() string {
 	if m.quitting {
@@ -71,7 +96,7 @@ func (m *Model) View() string {
 
 	if m.jumpToLineMode {
 		sections := []string{
-			headerStyle.Render("🏗️HyDE Config Manager"),
+			m.styles.header.Render("🏗️HyDE Config Manager"),
 			"Goto line: " + m.jumpToLineInput + "█",
 			m.renderMainContent(),
 			m.renderDetailsBar(),
@@ -82,12 +107,16 @@ func (m *Model) View() string {
 
 	var sections []string
 
-	header := headerStyle.Render("🏗️HyDE Config Manager")
+	header := m.styles.header.Render("🏗️HyDE Config Manager")
 	sections = append(sections, header)
 
 	mainContent := m.renderMainContent()
 	sections = append(sections, mainContent)
 
+	if len(m.editStatus) > 0 {
+		sections = append(sections, m.renderEditStatus())
+	}
+
 	detailsBar := m.renderDetailsBar()
 	sections = append(sections, detailsBar)
 
@@ -98,20 +127,15 @@ func (m *Model) View() string {
 }
 
 func (m *Model) renderDetailsBar() string {
-	ColorBrightCyan := lipgloss.Color("51")
-	ColorBrightBlack := lipgloss.Color("240")
-	ColorBrightGreen := lipgloss.Color("82")
-	ColorBrightRed := lipgloss.Color("196")
-
 	barStyle := lipgloss.NewStyle().
-		Foreground(ColorBrightCyan).
+		Foreground(m.theme.Accent).
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(ColorBrightCyan).
+		BorderForeground(m.theme.Accent).
 		Padding(0, 1)
-	sepStyle := lipgloss.NewStyle().Foreground(ColorBrightBlack)
-	valueStyle := lipgloss.NewStyle().Foreground(ColorBrightBlack)
-	okStyle := lipgloss.NewStyle().Foreground(ColorBrightGreen).Bold(true)
-	errStyle := lipgloss.NewStyle().Foreground(ColorBrightRed).Bold(true)
+	sepStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	valueStyle := lipgloss.NewStyle().Foreground(m.theme.Subtle)
+	okStyle := lipgloss.NewStyle().Foreground(m.theme.Success).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(m.theme.Error).Bold(true)
 
 	var info string
 
@@ -123,16 +147,15 @@ func (m *Model) renderDetailsBar() string {
 	switch m.focusArea {
 	case AppTabsFocus:
 		if activeAppTab >= 0 && activeAppTab < len(m.appList) && m.activeAppTab == activeAppTab {
-			appName := m.appList[activeAppTab]
-			appConfig := m.registry.Apps[appName]
-			if appConfig.Description != "" {
-				info = valueStyle.Render(appConfig.Description)
+			_, _, description := m.appInfo(m.appList[activeAppTab])
+			if description != "" {
+				info = valueStyle.Render(description)
 			}
 		}
 	case FileTrayFocus:
 		if m.activeFileTab >= 0 && m.activeFileTab < len(m.fileList) && m.focusArea == FileTrayFocus {
 			fileName := m.fileList[m.activeFileTab]
-			fileConfig := m.registry.Apps[m.currentApp].Files[fileName]
+			fileConfig, _ := m.lookupFile(fileName)
 			if fileConfig.Description != "" {
 				info = valueStyle.Render(fileConfig.Description)
 			}
@@ -146,12 +169,17 @@ func (m *Model) renderDetailsBar() string {
 					info += "  "
 				}
 				info += errStyle.Render("❌ Missing")
+				if fileConfig.HasTemplate() {
+					info += sepStyle.Render("  (preview shows template, Enter creates it)")
+				}
+			}
+			if m.isModified(m.fileTarget(fileName)) {
+				info += "  " + lipgloss.NewStyle().Foreground(m.theme.Highlight).Render("● Modified since opened")
 			}
 		}
 	case PreviewFocus:
 		if m.activeFileTab >= 0 && m.activeFileTab < len(m.fileList) && m.focusArea == PreviewFocus {
-			fileName := m.fileList[m.activeFileTab]
-			fileConfig := m.registry.Apps[m.currentApp].Files[fileName]
+			fileConfig, _ := m.lookupFile(m.fileList[m.activeFileTab])
 			if fileConfig.Description != "" {
 				info = valueStyle.Render(fileConfig.Description)
 			}
@@ -165,20 +193,45 @@ func (m *Model) renderDetailsBar() string {
 	return barStyle.Width(m.windowWidth - 5).Render(info)
 }
 
+func (m *Model) renderEditStatus() string {
+	paneStyle := lipgloss.NewStyle().
+		Foreground(m.theme.Dim).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(m.theme.Subtle).
+		Padding(0, 1).
+		Width(m.windowWidth - 5)
+
+	lineStyle := lipgloss.NewStyle().MaxWidth(m.windowWidth - 9)
+	var lines []string
+	for _, line := range m.editStatus {
+		lines = append(lines, lineStyle.Render(line))
+	}
+	return paneStyle.Render(strings.Join(lines, "\n"))
+}
+
 func (m *Model) renderMainContent() string {
 	var columns []string
 
+	// The style picker takes the focus border while it is open.
+	focus := m.focusArea
+	if m.stylePicker {
+		focus = AppTabsFocus
+	}
+
 	appCol := m.renderAppColumnNoBorder()
-	if m.focusArea == AppTabsFocus {
-		appCol = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).BorderRight(true).BorderTop(false).BorderBottom(false).BorderForeground(lipgloss.Color("51")).Bold(true).Width(m.tabWidth).Height(m.windowHeight - 8).Render(appCol)
+	if m.stylePicker {
+		appCol = m.renderStylePicker()
+	}
+	if focus == AppTabsFocus {
+		appCol = m.styles.focusedPane.Width(m.tabWidth).Height(m.mainHeight()).Render(appCol)
 	}
 	columns = append(columns, appCol)
 
 	fileColumnPresent := false
 	if m.expandedAppTab != -1 {
 		fileCol := m.renderFileColumnNoBorder()
-		if m.focusArea == FileTrayFocus {
-			fileCol = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).BorderRight(true).BorderTop(false).BorderBottom(false).BorderForeground(lipgloss.Color("51")).Bold(true).Width(m.trayWidth).Height(m.windowHeight - 8).Render(fileCol)
+		if focus == FileTrayFocus {
+			fileCol = m.styles.focusedPane.Width(m.trayWidth).Height(m.mainHeight()).Render(fileCol)
 		}
 		columns = append(columns, fileCol)
 		fileColumnPresent = true
@@ -194,28 +247,38 @@ func (m *Model) renderMainContent() string {
 		previewWidth = 10
 	}
 
-	parentHeight := m.windowHeight - 8
-	previewCol := m.renderPreviewColumnWithWidthAndHeight(previewWidth, parentHeight)
-	if m.focusArea == PreviewFocus {
-		previewCol = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderLeft(true).BorderRight(true).BorderTop(false).BorderBottom(false).BorderForeground(lipgloss.Color("51")).Bold(true).Width(previewWidth).Height(parentHeight).Render(previewCol)
+	parentHeight := m.mainHeight()
+	var previewCol string
+	if m.grepMode {
+		previewCol = m.renderGrepColumn(previewWidth, parentHeight)
+	} else {
+		previewCol = m.renderPreviewColumnWithWidthAndHeight(previewWidth, parentHeight)
+	}
+	if focus == PreviewFocus {
+		previewCol = m.styles.focusedPane.Width(previewWidth).Height(parentHeight).Render(previewCol)
 	}
 	columns = append(columns, previewCol)
 
 	mainBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("238")).
+		BorderForeground(m.theme.Border).
 		Width(m.windowWidth - 2).
 		Height(parentHeight)
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	if m.showHelp {
+		row = m.renderHelp(m.windowWidth-4, parentHeight)
+	}
 	return mainBoxStyle.Render(row)
 }
 
 func (m *Model) renderPreviewColumnWithWidthAndHeight(width, height int) string {
 	icon := "🔎"
 	headerText := fmt.Sprintf("%s Preview", icon)
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("51"))
-	headerLine := headerStyle.Render(headerText)
+	if m.diffBase != "" {
+		headerText = fmt.Sprintf("🔀 Diff vs %s", m.diffLabel)
+	}
+	headerLine := m.styles.columnHeader.Render(headerText)
 	separatorLine := strings.Repeat("─", width-2)
 
 	topElements := []string{headerLine, separatorLine}
@@ -234,10 +297,7 @@ func (m *Model) renderPreviewColumnWithWidthAndHeight(width, height int) string
 
 	m.previewViewport.Width = width
 	m.previewViewport.Height = height - topHeight
-	if m.expandedAppTab != -1 && len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
-		fileName := m.fileList[m.activeFileTab]
-		m.updatePreview(fileName)
-	} else {
+	if m.expandedAppTab == -1 || len(m.fileList) == 0 {
 		m.previewViewport.SetContent("")
 	}
 
@@ -250,25 +310,22 @@ func (m *Model) renderPreviewColumnWithWidthAndHeight(width, height int) string
 		highlightQuery = m.previewSearchBuffer
 	}
 	if highlightQuery != "" && contentBlock != "" {
-		query := highlightQuery
-		var re *regexp.Regexp
-		var err error
-		re, err = regexp.Compile("(?i)" + query)
-		if err != nil {
-			query = regexp.QuoteMeta(query)
-			re = regexp.MustCompile("(?i)" + query)
-		}
+		re := searchPattern(highlightQuery)
 		indices := re.FindAllStringIndex(contentBlock, -1)
-		current := m.previewMatchIndex
+		current := 0
+		if m.previewMatchIndex < len(m.previewMatchIndices) {
+			current = m.previewMatchIndices[m.previewMatchIndex]
+		}
 		var b strings.Builder
 		last := 0
-		for i, idx := range indices {
+		for _, idx := range indices {
 			b.WriteString(contentBlock[last:idx[0]])
 			match := contentBlock[idx[0]:idx[1]]
-			if i == current {
-				b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("226")).Bold(true).Render(match))
+			line := m.previewViewport.YOffset + strings.Count(contentBlock[:idx[0]], "\n") + 1
+			if line == current {
+				b.WriteString(m.styles.currentMatch.Render(match))
 			} else {
-				b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true).Underline(true).Render(match))
+				b.WriteString(m.styles.match.Render(match))
 			}
 			last = idx[1]
 		}
@@ -296,8 +353,7 @@ func (m *Model) renderAppColumnNoBorder() string {
 
 	headerIcon := normalizeIcon("⚙️", "⚙️")
 	header := fmt.Sprintf("%s Apps", headerIcon)
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("51"))
-	content = append(content, headerStyle.Render(header))
+	content = append(content, m.styles.columnHeader.Render(header))
 	content = append(content, strings.Repeat("─", m.tabWidth-2))
 
 	if m.searchMode && m.focusArea == AppTabsFocus {
@@ -306,27 +362,35 @@ func (m *Model) renderAppColumnNoBorder() string {
 	}
 
 	displayList := m.appList
-	if m.searchMode && len(m.filteredApps) > 0 && m.focusArea == AppTabsFocus {
+	ranking := m.searchMode && m.focusArea == AppTabsFocus && m.searchQuery != ""
+	if ranking {
 		displayList = m.filteredApps
+		if len(displayList) == 0 {
+			content = append(content, m.styles.inactiveTab.Render("No matches"))
+		}
 	}
 
 	for i, appName := range displayList {
-		appConfig := m.registry.Apps[appName]
-		icon := normalizeIcon(appConfig.Icon, "⚙️")
-		displayText := fmt.Sprintf("%s %s", icon, appName)
+		icon, label, _ := m.appInfo(appName)
+
+		// While ranking, the top result is the one Enter selects.
+		style := m.styles.inactiveTab
+		if (ranking && i == 0) || (!ranking && i == m.activeAppTab && m.focusArea == AppTabsFocus) {
+			style = m.styles.focusedTab
+		} else if !ranking && i == m.activeAppTab {
+			style = m.styles.activeTab
+		}
 
 		var styled string
-		if i == m.activeAppTab && m.focusArea == AppTabsFocus {
-			styled = focusedTabStyle.Render(displayText)
-		} else if i == m.activeAppTab {
-			styled = activeTabStyle.Render(displayText)
+		if ranking {
+			styled = style.Render(icon+" ") + highlightMatches(appName, m.appMatches[appName], style, style.Foreground(m.theme.Highlight).Underline(true))
 		} else {
-			styled = inactiveTabStyle.Render(displayText)
+			styled = style.Render(fmt.Sprintf("%s %s", icon, label))
 		}
 		content = append(content, styled)
 	}
 
-	maxHeight := m.windowHeight - 8
+	maxHeight := m.mainHeight()
 	for len(content) < maxHeight {
 		content = append(content, "")
 	}
@@ -338,8 +402,7 @@ func (m *Model) renderAppColumnNoBorder() string {
 func (m *Model) renderFileColumnNoBorder() string {
 	var content []string
 
-	appConfig := m.registry.Apps[m.currentApp]
-	icon := normalizeIcon(appConfig.Icon, "⚙️")
+	icon, _, _ := m.appInfo(m.currentApp)
 	header := fmt.Sprintf("%s Files", icon)
 	content = append(content, header)
 	content = append(content, strings.Repeat("─", m.trayWidth-2))
@@ -350,8 +413,12 @@ func (m *Model) renderFileColumnNoBorder() string {
 	}
 
 	displayList := m.fileList
-	if m.searchMode && len(m.filteredFiles) > 0 && m.focusArea == FileTrayFocus {
+	ranking := m.searchMode && m.focusArea == FileTrayFocus && m.searchQuery != ""
+	if ranking {
 		displayList = m.filteredFiles
+		if len(displayList) == 0 {
+			content = append(content, m.styles.missingFile.Render("No matches"))
+		}
 	}
 
 	for i, fileName := range displayList {
@@ -361,32 +428,37 @@ func (m *Model) renderFileColumnNoBorder() string {
 			fileIcon = "❌"
 		}
 		fileIcon = normalizeIcon(fileIcon, "📄")
-		displayText := fmt.Sprintf("%s %s", fileIcon, fileName)
+
+		// While ranking, the top result is the one Enter selects.
+		style := m.styles.inactiveFile
+		if !exists {
+			style = m.styles.missingFile
+		} else if (ranking && i == 0) || (!ranking && i == m.activeFileTab) {
+			style = m.styles.activeFile
+		}
+
+		// Files changed on disk since the selector opened are marked, as
+		// are pinned files.
+		mark := ""
+		if m.isModified(m.fileTarget(fileName)) {
+			mark = " ●"
+		}
+		if m.isPinned(fileName) {
+			mark += " 📌"
+		}
 
 		var styled string
-		if i == m.activeFileTab && m.focusArea == FileTrayFocus {
-			if exists {
-				styled = activeFileStyle.Render(displayText)
-			} else {
-				styled = missingFileStyle.Render(displayText)
-			}
-		} else if i == m.activeFileTab {
-			if exists {
-				styled = activeFileStyle.Render(displayText)
-			} else {
-				styled = missingFileStyle.Render(displayText)
-			}
+		if ranking {
+			plain := style.UnsetPadding()
+			name := highlightMatches(fileName, m.fileMatches[fileName], plain, plain.Foreground(m.theme.Highlight).Underline(true))
+			styled = lipgloss.NewStyle().Padding(0, 1).Render(plain.Render(fileIcon+" ") + name + plain.Render(mark))
 		} else {
-			if exists {
-				styled = inactiveFileStyle.Render(displayText)
-			} else {
-				styled = missingFileStyle.Render(displayText)
-			}
+			styled = style.Render(fmt.Sprintf("%s %s%s", fileIcon, fileName, mark))
 		}
 		content = append(content, styled)
 	}
 
-	maxHeight := m.windowHeight - 8
+	maxHeight := m.mainHeight()
 	for len(content) < maxHeight {
 		content = append(content, "")
 	}
@@ -420,42 +492,29 @@ func countWrappedLines(s string, width int) int {
 	return count
 }
 
+// renderHelp lists every key binding, in place of the columns.
+func (m *Model) renderHelp(width, height int) string {
+	m.help.Width = width
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		m.styles.header.Width(width).Render("⌨️ Key bindings"),
+		"",
+		m.help.FullHelpView(m.keys.fullHelp()),
+	)
+	return lipgloss.NewStyle().Width(width).Height(height).Padding(0, 1).Render(content)
+}
+
 func (m *Model) renderFooter() string {
-	var statusItems []string
+	m.help.Width = m.windowWidth - 2
+	statusText := m.help.ShortHelpView(m.footerBindings())
 
 	if m.searchMode {
-
-		if m.focusArea == AppTabsFocus {
-			statusItems = append(statusItems, fmt.Sprintf("Search apps: %s█", m.searchQuery))
-			statusItems = append(statusItems, " Enter: confirm")
-			statusItems = append(statusItems, " Esc: cancel")
-		} else if m.focusArea == FileTrayFocus {
-			statusItems = append(statusItems, fmt.Sprintf("Search files: %s█", m.searchQuery))
-			statusItems = append(statusItems, " Enter: confirm")
-			statusItems = append(statusItems, " Esc: cancel")
-		}
-	} else {
 		switch m.focusArea {
 		case AppTabsFocus:
-			statusItems = append(statusItems, "↑/↓: navigate")
-			statusItems = append(statusItems, " Enter/Space: expand")
+			statusText = fmt.Sprintf("Search apps: %s█  ", m.searchQuery) + statusText
 		case FileTrayFocus:
-			statusItems = append(statusItems, "↑/↓: navigate")
-			statusItems = append(statusItems, " Enter: select")
-			statusItems = append(statusItems, " ←: back to apps")
-		case PreviewFocus:
-			statusItems = append(statusItems, "PgUp/PgDn: scroll")
-			statusItems = append(statusItems, " ←/→: horizontal scroll")
-			statusItems = append(statusItems, " ctrl+l: toggle line numbers")
-		}
-		statusItems = append(statusItems, " Tab: cycle focus")
-		statusItems = append(statusItems, " /: search")
-		if m.debug {
-			statusItems = append(statusItems, " ctrl+d: debug")
+			statusText = fmt.Sprintf("Search files: %s█  ", m.searchQuery) + statusText
 		}
-		statusItems = append(statusItems, " q: quit")
 	}
 
-	statusText := strings.Join(statusItems, " ")
-	return footerStyle.Width(m.windowWidth).Render(statusText)
+	return m.styles.footer.Width(m.windowWidth).Render(statusText)
 }
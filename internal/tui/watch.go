@@ -0,0 +1,197 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"hydectl/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce groups the bursts of events editors produce when saving.
+const watchDebounce = 150 * time.Millisecond
+
+type filesChangedMsg struct {
+	paths []string
+}
+
+// fileWatcher reports changes to the registered config files. It watches
+// their directories rather than the files, so files that are created or
+// replaced on save are picked up too.
+type fileWatcher struct {
+	watcher *fsnotify.Watcher
+	paths   map[string]bool
+	// dirs holds the directories of the registered files and whether they
+	// are watched. One that does not exist yet is waited for by watching its
+	// nearest existing ancestor.
+	dirs    map[string]bool
+	changes chan filesChangedMsg
+	done    chan struct{}
+}
+
+func newFileWatcher(registry *config.OrderedConfigRegistry) (*fileWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &fileWatcher{
+		watcher: watcher,
+		paths:   make(map[string]bool),
+		dirs:    make(map[string]bool),
+		changes: make(chan filesChangedMsg),
+		done:    make(chan struct{}),
+	}
+	for _, appName := range registry.AppsOrder {
+		for _, fileConfig := range registry.Apps[appName].Files {
+			path := filepath.Clean(config.ExpandPath(fileConfig.Path))
+			w.paths[path] = true
+			w.dirs[filepath.Dir(path)] = false
+		}
+	}
+	w.watchDirs()
+
+	go w.run()
+	return w, nil
+}
+
+// watchDirs adds watches for the directories not watched yet, or for their
+// nearest existing ancestor while they are missing. It returns the registered
+// files in directories that became watched, as they may have been created
+// before the watch was in place.
+func (w *fileWatcher) watchDirs() []string {
+	var found []string
+	for dir, watched := range w.dirs {
+		if watched {
+			continue
+		}
+		if _, err := os.Stat(dir); err == nil {
+			// A directory that can't be watched only loses live refresh.
+			if w.watcher.Add(dir) == nil {
+				w.dirs[dir] = true
+				found = append(found, w.existingFiles(dir)...)
+			}
+			continue
+		}
+		for parent := filepath.Dir(dir); parent != filepath.Dir(parent); parent = filepath.Dir(parent) {
+			if _, err := os.Stat(parent); err == nil {
+				_ = w.watcher.Add(parent)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// existingFiles returns the registered files present in dir.
+func (w *fileWatcher) existingFiles(dir string) []string {
+	var files []string
+	for path := range w.paths {
+		if filepath.Dir(path) != dir {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+func (w *fileWatcher) run() {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			removed := event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+			if removed && w.dirs[name] {
+				// The watch went away with the directory; wait for it again.
+				w.dirs[name] = false
+			}
+			if removed || event.Has(fsnotify.Create) {
+				for _, path := range w.watchDirs() {
+					pending[path] = true
+					timer.Reset(watchDebounce)
+				}
+			}
+			if event.Op == fsnotify.Chmod || !w.paths[name] {
+				continue
+			}
+			pending[name] = true
+			timer.Reset(watchDebounce)
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			msg := filesChangedMsg{}
+			for path := range pending {
+				msg.paths = append(msg.paths, path)
+			}
+			pending = make(map[string]bool)
+			select {
+			case w.changes <- msg:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// wait returns a command delivering the next batch of changes.
+func (w *fileWatcher) wait() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-w.changes:
+			return msg
+		case <-w.done:
+			return nil
+		}
+	}
+}
+
+func (w *fileWatcher) Close() error {
+	close(w.done)
+	return w.watcher.Close()
+}
+
+// handleFilesChanged refreshes existence markers and the preview after files
+// changed on disk, and flags the files that were modified since the TUI
+// opened.
+func (m *Model) handleFilesChanged(msg filesChangedMsg) tea.Cmd {
+	changed := make(map[string]bool, len(msg.paths))
+	for _, path := range msg.paths {
+		changed[path] = true
+	}
+
+	for _, appName := range m.registry.AppsOrder {
+		for fileName, fileConfig := range m.registry.Apps[appName].Files {
+			path := filepath.Clean(config.ExpandPath(fileConfig.Path))
+			if !changed[path] {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || info.ModTime().After(m.openedAt) {
+				m.modifiedFiles[appName+"/"+fileName] = true
+			}
+		}
+	}
+
+	m.checkFileExists()
+	if len(m.fileList) > 0 && m.activeFileTab < len(m.fileList) {
+		m.updatePreview(m.fileList[m.activeFileTab])
+	}
+	return m.watcher.wait()
+}
+
+func (m *Model) isModified(appName, fileName string) bool {
+	return m.modifiedFiles[appName+"/"+fileName]
+}